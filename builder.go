@@ -7,9 +7,11 @@
 package form
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"html/template"
+	"reflect"
 	"strings"
 )
 
@@ -48,23 +50,202 @@ import (
 // For a much more thorough set of examples, see the examples directory.
 // There is even an example illustrating how the gorilla/schema package can
 // be used to parse forms that are created by the Builder.
+//
+// The InputTemplate can also define other named templates that are used in
+// place of it for specific fields. Templates named after an input type,
+// eg input:checkbox or input:select, are used for fields of that type,
+// and a template tag can be used to pick a template for a single field,
+// eg `form:"template=wide"`. Eg:
+//
+//   tpl := template.Must(template.New("").Parse(`
+//     <input type="{{.Type}}" name="{{.Name}}" value="{{.Formatted}}">
+//     {{define "input:checkbox"}}
+//       <input type="checkbox" name="{{.Name}}"{{if .Value}} checked{{end}}>
+//     {{end}}
+//     {{define "input:textarea"}}
+//       <textarea name="{{.Name}}">{{.Formatted}}</textarea>
+//     {{end}}
+//   `))
+//
+// This makes it possible to write one template per type of control
+// rather than a single template with an if statement for each of them.
 type Builder struct {
 	InputTemplate *template.Template
+
+	// TypeMapper is used to determine the input type of fields that don't
+	// have a type tag. If nil, DefaultTypeMapper is used, which infers
+	// types like number and checkbox from each field's Go type.
+	TypeMapper TypeMapper
+
+	// LeafTypes are struct types that should be rendered as a single input
+	// rather than having each of their fields rendered. time.Time, the
+	// sql.Null* types, and any types implementing encoding.TextMarshaler
+	// are always treated as leaf types, so this is only needed for other
+	// types.
+	LeafTypes []reflect.Type
+
+	// Codecs are used to format values of a given type when rendering and
+	// to parse them when decoding. Struct types with a Codec are rendered
+	// as a single input, just like LeafTypes. See the Codec type for more
+	// info.
+	Codecs map[reflect.Type]Codec
+
+	// BracketIndexes changes how elements of slices and arrays are named.
+	// By default the index is separated by a period, eg Items.0.Name, but
+	// when this is true the index is wrapped in brackets, eg Items[0].Name.
+	// Indexes are always wrapped in brackets with BracketNaming.
+	BracketIndexes bool
+
+	// Naming determines how names are joined together to build the name of
+	// each input. See the Naming type for the options. Decode uses the
+	// same Naming, so forms will round-trip regardless of the choice.
+	Naming Naming
+
+	// UncheckMissing, when true, has Decode set checkbox fields that are
+	// missing from the submitted values to false, as browsers don't submit
+	// unchecked checkboxes at all. This applies to fields of type bool,
+	// *bool, and sql.NullBool rendered as checkboxes. Leave it off when
+	// decoding partial submissions, eg a PATCH with only changed fields.
+	UncheckMissing bool
+
+	// NameFunc, if set, is used to convert each Go field name into the name
+	// used for its input, eg SnakeCase. It isn't used for fields with a
+	// name tag.
+	NameFunc func(field string) string
+
+	// NameTags are other struct tags whose names should be used when a
+	// field doesn't have a form name tag, eg []string{"json", "schema"}.
+	// The first tag present on a field is used, and it takes precedence
+	// over the NameFunc.
+	NameTags []string
+
+	// SortMapKeys is used to order the keys of map fields, which are
+	// rendered one field per key. If nil, keys are sorted alphabetically.
+	SortMapKeys func(keys []string)
+
+	// MapKeyLabel is used to determine the label of each map entry when
+	// the map field doesn't have a label tag. If nil, the key is used.
+	MapKeyLabel func(key string) string
+
+	// LabelFunc is used to determine the label of fields and groups that
+	// don't have a label tag, and is provided with the Go field name. If
+	// nil, Humanize is used, eg ZipCode is labeled "Zip code".
+	LabelFunc func(field string) string
+
+	// ExplicitPlaceholders, when true, leaves placeholders empty unless a
+	// field has a placeholder tag. By default the placeholder is the same
+	// as the label.
+	ExplicitPlaceholders bool
+
+	// Translator, if set, is used to translate message keys in tags and
+	// error messages, eg `form:"label=@user.email"`, into the Locale.
+	// Without a Translator, message keys are rendered as-is. See the
+	// Translator type for more info.
+	Translator Translator
+
+	// Locale is the locale passed to the Translator. Use InputsLocale, or
+	// WithLocale with InputsContext, to render in a different locale with
+	// the same Builder.
+	Locale string
+
+	// FieldFilter, if set, is called with each field before it is rendered
+	// and fields it returns false for are left out, eg because the current
+	// user isn't allowed to change them. Decode and Validate use the same
+	// filter, so those fields are never set or validated either. ctx is the
+	// context provided to a Context method, like InputsContext or
+	// DecodeContext, or context.Background() otherwise.
+	FieldFilter func(ctx context.Context, f Field) bool
+
+	// TokenFunc, if set, returns the name and value of a hidden input that
+	// should be part of each form, eg a CSRF token, and is rendered by
+	// TokenInput and the token_for template function.
+	TokenFunc func(ctx context.Context) (name, value string, err error)
+
+	// GroupTemplate, if set, is executed around the inputs of each nested
+	// struct when rendering with Inputs. It is provided a RenderedGroup,
+	// which makes it possible to wrap nested structs in a fieldset. Eg:
+	//
+	//   <fieldset>
+	//     <legend>{{.Label}}</legend>
+	//     {{.Children}}
+	//   </fieldset>
+	//
+	// Groups nested in other groups are rendered as part of the outer
+	// group's Children. Each struct element of a slice or array is a group
+	// of its own, eg Items.0, so every row can be wrapped separately.
+	GroupTemplate *template.Template
+
+	// ErrorsTemplate, if set, is executed before the inputs rendered by
+	// Inputs when any of the errors provided aren't field errors. It is
+	// provided a []string with the message of each of these errors, eg:
+	//
+	//   <ul class="errors">
+	//     {{range .}}<li>{{.}}</li>{{end}}
+	//   </ul>
+	//
+	// See FormErrors for more info.
+	ErrorsTemplate *template.Template
+
+	// FormErrorFunc, if set, is called by Inputs with each error that isn't
+	// a field error, eg to log it.
+	FormErrorFunc func(err error)
+
+	// Messages is a catalog of error messages keyed by error code. It is
+	// used in place of the message of any field error implementing the
+	// CodedError interface, like a *ValidationError, whose code is in the
+	// catalog. Params are referenced by name, eg:
+	//
+	//   Messages: map[string]string{
+	//     "minlen": "needs {minlen} or more characters",
+	//     "min":    "@errors.min",
+	//   }
+	//
+	// Messages can also be message keys, which are translated before
+	// their params are filled in.
+	Messages map[string]string
+
+	// MaxDepth is the deepest that structs and maps may be nested, counting
+	// the value passed into the Builder. Going any deeper results in a
+	// *DepthError rather than the stack overflowing. If zero,
+	// DefaultMaxDepth is used.
+	MaxDepth int
+
+	// ctx is set on copies of the Builder made by the Context methods, and
+	// is provided to hooks. See withContext.
+	ctx context.Context
+}
+
+// DefaultMaxDepth is the MaxDepth used by a Builder that doesn't set one.
+const DefaultMaxDepth = 32
+
+func (b *Builder) maxDepth() int {
+	if b.MaxDepth > 0 {
+		return b.MaxDepth
+	}
+	return DefaultMaxDepth
+}
+
+// RenderedGroup is the data provided to the Builder's GroupTemplate. It
+// has all of the information about the group, as well as the HTML of
+// every input (and nested group) inside of it.
+type RenderedGroup struct {
+	Group
+	Children template.HTML
 }
 
 // Inputs will parse the provided struct into fields and then execute the
 // Builder.InputTemplate with each field. The returned HTML is simply
 // all of these results appended one after another.
 //
-// Inputs only accepts structs for the first argument. This may change
-// later, but that is all I needed for my use case so it is what it does.
-// If you need support for something else like maps let me know.
+// Inputs only accepts structs and maps with string keys for the first
+// argument. Each key of a map is rendered as its own field, in sorted
+// order by default.
 //
 // Inputs' second argument - errs - will be used to render errors for
 // individual fields. This is done by looking for errors that implement
-// the fieldError interface:
+// the FieldErrorer interface:
 //
-//   type fieldError interface {
+//   type FieldErrorer interface {
 //    	FieldError() (field, err string)
 //   }
 //
@@ -75,33 +256,154 @@ type Builder struct {
 // is rendering. See examples/errors/errors.go for an example of this in
 // action.
 //
-// This interface is not exported and you can pass other errors into Inputs
-// but they currently won't be used.
+// The FieldError type and Errors collection implement this interface and
+// are the easiest way to build errors for a form. Other errors are
+// treated as form-level errors, which are rendered with the Builder's
+// ErrorsTemplate. See FormErrors for more info.
+//
+// Use InputsContext to provide per-request state to the Builder's hooks.
 func (b *Builder) Inputs(v interface{}, errs ...error) (template.HTML, error) {
+	return b.InputsContext(context.Background(), v, errs...)
+}
+
+// inputs implements InputsContext for a Builder returned by withContext.
+func (b *Builder) inputs(v interface{}, errs []error) (template.HTML, error) {
 	tpl, err := b.InputTemplate.Clone()
 	if err != nil {
 		return "", err
 	}
-	fields := fields(v)
-	errors := fieldErrors(errs)
-	var html template.HTML
-	for _, field := range fields {
+	fields, err := b.filteredFields(v)
+	if err != nil {
+		return "", err
+	}
+	errors, formErrs := b.fieldErrors(errs)
+	summary, err := b.renderFormErrors(formErrs)
+	if err != nil {
+		return "", err
+	}
+
+	// Fields of the same nested struct are always next to each other, so
+	// we keep a stack of the groups that are currently open along with the
+	// HTML rendered inside of each. open[0] is the top level and never has
+	// a group, and it is the only one used without a GroupTemplate.
+	open := []RenderedGroup{{}}
+	closeGroup := func() error {
+		g := open[len(open)-1]
+		open = open[:len(open)-1]
 		var sb strings.Builder
-		tpl.Funcs(template.FuncMap{
-			"errors": func() []string {
-				if errs, ok := errors[field.Name]; ok {
-					return errs
-				}
-				return nil
-			},
-		})
-		err := tpl.Execute(&sb, field)
+		if err := b.GroupTemplate.Execute(&sb, g); err != nil {
+			return err
+		}
+		open[len(open)-1].Children += template.HTML(sb.String())
+		return nil
+	}
+	for _, field := range fields {
+		groups := field.Groups
+		if b.GroupTemplate == nil {
+			groups = nil
+		}
+		// Close any groups that this field isn't a part of, then open the
+		// ones it is part of that aren't open yet.
+		same := 0
+		for same < len(groups) && same+1 < len(open) && open[same+1].Name == groups[same].Name {
+			same++
+		}
+		for len(open) > same+1 {
+			if err := closeGroup(); err != nil {
+				return "", err
+			}
+		}
+		for _, g := range groups[same:] {
+			open = append(open, RenderedGroup{Group: g})
+		}
+		fieldHTML, err := render(tpl, field, errors)
 		if err != nil {
 			return "", err
 		}
-		html = html + template.HTML(sb.String())
+		open[len(open)-1].Children += fieldHTML
+	}
+	for len(open) > 1 {
+		if err := closeGroup(); err != nil {
+			return "", err
+		}
+	}
+	return summary + open[0].Children, nil
+}
+
+// Fields parses the provided struct (or map) into the fields that the
+// Inputs method would render, without rendering them. This is useful when
+// you want to build a custom layout, such as a grid or tabs, as you can
+// range over the fields in a template and render each with the Input
+// method (or the input_for template function). It also makes it easy to
+// test the information provided about each field.
+//
+// An *UnsupportedTypeError, *InvalidValueError, *CycleError, or
+// *DepthError is returned if v, or any of its fields, can't be rendered.
+// An error is also returned if a minlen or maxlen tag isn't a whole number.
+func (b *Builder) Fields(v interface{}) ([]Field, error) {
+	return b.FieldsContext(context.Background(), v)
+}
+
+// Input executes the Builder.InputTemplate with a single field, typically
+// one returned by the Fields method. Errors are handled exactly as they
+// are by the Inputs method.
+func (b *Builder) Input(f Field, errs ...error) (template.HTML, error) {
+	return b.InputContext(context.Background(), f, errs...)
+}
+
+// input implements InputContext for a Builder returned by withContext.
+func (b *Builder) input(f Field, errs []error) (template.HTML, error) {
+	tpl, err := b.InputTemplate.Clone()
+	if err != nil {
+		return "", err
+	}
+	errors, _ := b.fieldErrors(errs)
+	return render(tpl, f, errors)
+}
+
+// render executes tpl, which should be a clone of the InputTemplate, with
+// the field f.
+func render(tpl *template.Template, f Field, errors map[string][]ErrorEntry) (template.HTML, error) {
+	var sb strings.Builder
+	tpl.Funcs(template.FuncMap{
+		"errors": func() []string {
+			var ret []string
+			for _, e := range errors[f.Name] {
+				ret = append(ret, e.Message)
+			}
+			return ret
+		},
+		"error_entries": func() []ErrorEntry {
+			return errors[f.Name]
+		},
+	})
+	t, err := templateFor(tpl, f)
+	if err != nil {
+		return "", err
+	}
+	err = t.Execute(&sb, f)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(sb.String()), nil
+}
+
+// templateFor returns the template from the InputTemplate set that should
+// be used to render f. Templates named via a template tag must exist, but
+// otherwise we look for a template named after the field's type, eg
+// input:checkbox, and fall back to the InputTemplate itself.
+func templateFor(tpl *template.Template, f Field) (*template.Template, error) {
+	if f.Template != "" {
+		t := tpl.Lookup(f.Template)
+		if t == nil {
+			return nil, fmt.Errorf("form: template %q for field %v is not defined", f.Template, f.Name)
+		}
+		return t, nil
+	}
+	if t := tpl.Lookup("input:" + f.Type); t != nil {
+		return t, nil
 	}
-	return html, nil
+	return tpl, nil
 }
 
 // FuncMap returns a template.FuncMap that defines both the inputs_for and
@@ -109,12 +411,48 @@ func (b *Builder) Inputs(v interface{}, errs ...error) (template.HTML, error) {
 // latter is provided via a closure because variadic parameters and the
 // template package don't play very nicely and this just simplifies things
 // a lot for end users of the form package.
+//
+// The fields_for, input_for, and input_and_errors_for functions are also
+// provided for templates that want to lay out fields themselves. Eg:
+//
+//   {{range fields_for .Form}}
+//     <div class="col">{{input_and_errors_for . $.Errors}}</div>
+//   {{end}}
+//
+// Each function has a _context variant, eg inputs_for_context, that
+// takes a context.Context as its first argument and calls the matching
+// Context method. token_for renders the hidden input from TokenInput, and
+// form_errors returns the messages of errors that aren't field errors (see
+// FormErrors). Eg:
+//
+//   <form>
+//     {{token_for .Ctx}}
+//     {{inputs_and_errors_for_context .Ctx .Form .Errors}}
+//   </form>
 func (b *Builder) FuncMap() template.FuncMap {
 	return template.FuncMap{
 		"inputs_for": b.Inputs,
 		"inputs_and_errors_for": func(v interface{}, errs []error) (template.HTML, error) {
 			return b.Inputs(v, errs...)
 		},
+		"fields_for": b.Fields,
+		"input_for":  b.Input,
+		"input_and_errors_for": func(f Field, errs []error) (template.HTML, error) {
+			return b.Input(f, errs...)
+		},
+		"inputs_for_context": b.InputsContext,
+		"inputs_and_errors_for_context": func(ctx context.Context, v interface{}, errs []error) (template.HTML, error) {
+			return b.InputsContext(ctx, v, errs...)
+		},
+		"fields_for_context": b.FieldsContext,
+		"input_for_context":  b.InputContext,
+		"input_and_errors_for_context": func(ctx context.Context, f Field, errs []error) (template.HTML, error) {
+			return b.InputContext(ctx, f, errs...)
+		},
+		"token_for": b.TokenInput,
+		"form_errors": func(errs []error) []string {
+			return b.FormErrors(errs...)
+		},
 	}
 }
 
@@ -124,12 +462,14 @@ func (b *Builder) FuncMap() template.FuncMap {
 // template is parsed. We clearly don't know whether a field has an error
 // or not until it is parsed via the Inputs method call, so this basically
 // just provides a stubbed out errors function that returns nil so the template
-// compiles correctly.
+// compiles correctly. The same is done for the error_entries function,
+// which returns an ErrorEntry for each error rather than just its message.
 //
 // See examples/errors/errors.go for a clear example of this being used.
 func FuncMap() template.FuncMap {
 	return template.FuncMap{
-		"errors": ErrorsStub,
+		"errors":        ErrorsStub,
+		"error_entries": ErrorEntriesStub,
 	}
 }
 
@@ -146,52 +486,95 @@ func ErrorsStub() []string {
 	return nil
 }
 
-// fieldError is an interface defining an error that represents something
-// wrong with a particular struct field. The name should correspond to the
-// name value used when building the HTML form, which is currently a period
-// separated list of all fields that lead up to the particular field.
-// Eg, in the following struct the Mouse field would have a key of Cat.Mouse:
-//
-//   type Dog struct {
-//     Cat: struct{
-//       Mouse string
-//     }
-//   }
-//
-// The top level Dog struct name is not used because this is unnecessary,
-// but any other nested struct names are necessary to properly determine
-// the field.
-//
-// It should also be noted that if you provide a custom field name, that
-// name should also be used in fieldError implementations.
-type fieldError interface {
-	FieldError() (field, err string)
+// ErrorEntriesStub is the same as ErrorsStub, but for the error_entries
+// function.
+func ErrorEntriesStub() []ErrorEntry {
+	return nil
 }
 
-// errors will build a map where each key is the field name, and each
-// value is a slice of strings representing errors with that field.
+// fieldErrors will build a map where each key is the field name, and each
+// value is a slice of entries representing errors with that field.
 //
-// It works by looking for errors that implement the following interface:
-//
-//   interface {
-//     FieldError() (string, string)
-//   }
-//
-// Where the first string returned is expected to be the field name, and
-// the second return value is expected to be an error with that field.
-// Any errors that implement this interface are then used to build the
-// slice of errors for the field, meaning you can provide multiple
-// errors for the same field and all will be utilized.
-func fieldErrors(errs []error) map[string][]string {
-	ret := make(map[string][]string)
-	for _, err := range errs {
-		var fe fieldError
+// It works by looking for errors that implement the FieldErrorer
+// interface. Any errors that implement this interface are then used to
+// build the slice of errors for the field, meaning you can provide
+// multiple errors for the same field and all will be utilized. Errors
+// that contain several others, like those returned by errors.Join, are
+// expanded first (see flattenErrors). All other errors are returned as
+// form-level errors. See errorEntry for how each message is determined.
+func (b *Builder) fieldErrors(errs []error) (fields map[string][]ErrorEntry, form []error) {
+	fields = make(map[string][]ErrorEntry)
+	for _, err := range flattenErrors(errs) {
+		var fe FieldErrorer
 		if !errors.As(err, &fe) {
-			fmt.Println(err, "isnt field error")
+			form = append(form, err)
 			continue
 		}
-		field, fieldErr := fe.FieldError()
-		ret[field] = append(ret[field], fieldErr)
+		field, entry := b.errorEntry(fe)
+		fields[field] = append(fields[field], entry)
+	}
+	return fields, form
+}
+
+// errorEntry returns the field and entry for fe. If fe is a CodedError
+// and its code is in the Builder's Messages, that message is used with
+// the error's params filled in. Otherwise the message returned by fe is
+// used. Either way, messages that are message keys, eg @errors.taken, are
+// translated with the Translator.
+func (b *Builder) errorEntry(fe FieldErrorer) (field string, e ErrorEntry) {
+	field, e.Message = fe.FieldError()
+	if ce, ok := fe.(CodedError); ok {
+		e.Code, e.Params = ce.ErrorCode()
+		if msg, ok := b.Messages[e.Code]; ok && e.Code != "" {
+			e.Message = interpolate(b.translate(msg), e.Params)
+			return field, e
+		}
+	}
+	e.Message = b.translate(e.Message)
+	return field, e
+}
+
+// FormErrors returns the messages of every error in errs that isn't a
+// field error, such as a failure to save a record, so that they can be
+// shown as part of the form rather than next to a specific field. It is
+// provided to templates as the form_errors function. Eg:
+//
+//   {{range form_errors .Errors}}
+//     <p class="error">{{.}}</p>
+//   {{end}}
+func (b *Builder) FormErrors(errs ...error) []string {
+	_, form := b.fieldErrors(errs)
+	return b.messages(form)
+}
+
+// messages returns the translated message of each error.
+func (b *Builder) messages(errs []error) []string {
+	if len(errs) == 0 {
+		return nil
+	}
+	ret := make([]string, 0, len(errs))
+	for _, err := range errs {
+		ret = append(ret, b.translate(err.Error()))
 	}
 	return ret
 }
+
+// renderFormErrors executes the ErrorsTemplate with the messages of the
+// form-level errors in errs, and reports each of them to the
+// FormErrorFunc. Nothing is rendered if there aren't any form-level
+// errors.
+func (b *Builder) renderFormErrors(errs []error) (template.HTML, error) {
+	if b.FormErrorFunc != nil {
+		for _, err := range errs {
+			b.FormErrorFunc(err)
+		}
+	}
+	if b.ErrorsTemplate == nil || len(errs) == 0 {
+		return "", nil
+	}
+	var sb strings.Builder
+	if err := b.ErrorsTemplate.Execute(&sb, b.messages(errs)); err != nil {
+		return "", err
+	}
+	return template.HTML(sb.String()), nil
+}
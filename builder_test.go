@@ -70,12 +70,12 @@ func TestBuilder_Inputs_errors(t *testing.T) {
 		field: "field",
 		err:   "err",
 	}
-	var fe fieldError
+	var fe FieldErrorer
 	if !errors.As(tfe, &fe) {
-		t.Fatalf("As(testFieldError, fieldError) = false")
+		t.Fatalf("As(testFieldError, FieldErrorer) = false")
 	}
 	if !errors.As(fmt.Errorf("wrapped: %w", tfe), &fe) {
-		t.Fatalf("As(wrapped, fieldError) = false")
+		t.Fatalf("As(wrapped, FieldErrorer) = false")
 	}
 
 	tpl := template.Must(template.New("").Funcs(FuncMap()).Parse(strings.TrimSpace(`
@@ -130,3 +130,214 @@ func TestBuilder_Inputs_errors(t *testing.T) {
 		})
 	}
 }
+
+func TestBuilder_Inputs_templates(t *testing.T) {
+	tpl := template.Must(template.New("").Parse(strings.TrimSpace(`
+		{{define "input:checkbox"}}<input type="checkbox" name="{{.Name}}"{{if .Value}} checked{{end}}>{{end}}
+		{{- define "input:textarea"}}<textarea name="{{.Name}}">{{.Formatted}}</textarea>{{end}}
+		{{- define "wide"}}<input class="wide" name="{{.Name}}">{{end -}}
+		<input name="{{.Name}}">
+	`)))
+	tests := []struct {
+		name string
+		arg  interface{}
+		want template.HTML
+	}{
+		{
+			name: "by type",
+			arg: struct {
+				Name   string
+				Admin  bool
+				Bio    string `form:"type=textarea"`
+				Agreed bool
+			}{Name: "Michael", Admin: true, Bio: "Regional manager"},
+			want: template.HTML(strings.Join([]string{
+				`<input name="Name">`,
+				`<input type="checkbox" name="Admin" checked>`,
+				`<textarea name="Bio">Regional manager</textarea>`,
+				`<input type="checkbox" name="Agreed">`,
+			}, "")),
+		}, {
+			name: "by tag",
+			arg: struct {
+				Name  string `form:"template=wide"`
+				Admin bool   `form:"template=wide"`
+			}{},
+			want: template.HTML(`<input class="wide" name="Name"><input class="wide" name="Admin">`),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &Builder{
+				InputTemplate: tpl,
+			}
+			got, err := b.Inputs(tc.arg)
+			if err != nil {
+				t.Errorf("Builder.Inputs() err = %v, want %v", err, nil)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Builder.Inputs() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	b := &Builder{InputTemplate: tpl}
+	_, err := b.Inputs(struct {
+		Name string `form:"template=missing"`
+	}{})
+	if err == nil {
+		t.Errorf("Builder.Inputs() err = nil, want an error for a missing template")
+	}
+}
+
+func TestBuilder_Fields(t *testing.T) {
+	var b Builder
+	got, err := b.Fields(struct {
+		Name  string `form:"label=Full Name"`
+		Email string `form:"type=email;required"`
+	}{Name: "Michael Scott"})
+	if err != nil {
+		t.Fatalf("Builder.Fields() err = %v, want %v", err, nil)
+	}
+	want := []Field{
+		{
+			Name:        "Name",
+			Label:       "Full Name",
+			Placeholder: "Full Name",
+			Type:        "text",
+			Value:       "Michael Scott",
+			Formatted:   "Michael Scott",
+		}, {
+			Name:        "Email",
+			Label:       "Email",
+			Placeholder: "Email",
+			Type:        "email",
+			Value:       "",
+			Required:    true,
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Builder.Fields() = %+v, want %+v", got, want)
+	}
+}
+
+func TestBuilder_FuncMap_fields_for(t *testing.T) {
+	b := &Builder{
+		InputTemplate: template.Must(template.New("").Funcs(FuncMap()).Parse(
+			`<input name="{{.Name}}">{{range errors}}<p>{{.}}</p>{{end}}`,
+		)),
+	}
+	tpl := template.Must(template.New("").Funcs(b.FuncMap()).Parse(
+		`{{range fields_for .Form}}<div>{{input_and_errors_for . $.Errors}}</div>{{end}}` +
+			`{{range fields_for .Form}}{{input_for .}}{{end}}`,
+	))
+	var sb strings.Builder
+	err := tpl.Execute(&sb, map[string]interface{}{
+		"Form": struct {
+			Name  string
+			Email string
+		}{},
+		"Errors": []error{testFieldError{"Email", "is required"}},
+	})
+	if err != nil {
+		t.Fatalf("Execute() err = %v, want %v", err, nil)
+	}
+	want := `<div><input name="Name"></div><div><input name="Email"><p>is required</p></div>` +
+		`<input name="Name"><input name="Email">`
+	if got := sb.String(); got != want {
+		t.Errorf("Execute() = %v, want %v", got, want)
+	}
+}
+
+func TestBuilder_Inputs_unsupported(t *testing.T) {
+	b := &Builder{InputTemplate: template.Must(template.New("").Parse(`<input name="{{.Name}}">`))}
+	tpl := template.Must(template.New("").Funcs(b.FuncMap()).Parse(`{{inputs_for .}}`))
+	err := tpl.Execute(&strings.Builder{}, []string{"not", "a", "struct"})
+	var ute *UnsupportedTypeError
+	if !errors.As(err, &ute) {
+		t.Fatalf("Execute() err = %v, want an *UnsupportedTypeError", err)
+	}
+	if _, err := b.Inputs(nil); err == nil {
+		t.Errorf("Builder.Inputs(nil) err = nil, want an error")
+	}
+}
+
+func TestBuilder_Inputs_groups(t *testing.T) {
+	type address struct {
+		Street1 string
+		Zip     string
+	}
+	type contact struct {
+		Email string
+		Home  address `form:"legend=Home address"`
+	}
+	b := &Builder{
+		InputTemplate: template.Must(template.New("").Parse(`<input name="{{.Name}}">`)),
+		GroupTemplate: template.Must(template.New("").Parse(
+			`<fieldset data-name="{{.Name}}" data-depth="{{.Depth}}"><legend>{{.Label}}</legend>{{.Children}}</fieldset>`,
+		)),
+	}
+	got, err := b.Inputs(struct {
+		Name     string
+		Billing  address `form:"name=billing"`
+		Shipping address
+		Contact  contact
+		Lines    []address `form:"legend=Line"`
+		Places   map[string]address
+		Notes    string
+	}{Lines: make([]address, 2), Places: map[string]address{"home": {}, "work": {}}})
+	if err != nil {
+		t.Fatalf("Builder.Inputs() err = %v, want %v", err, nil)
+	}
+	want := template.HTML(strings.Join([]string{
+		`<input name="Name">`,
+		`<fieldset data-name="billing" data-depth="1"><legend>Billing</legend>`,
+		`<input name="billing.Street1"><input name="billing.Zip">`,
+		`</fieldset>`,
+		`<fieldset data-name="Shipping" data-depth="1"><legend>Shipping</legend>`,
+		`<input name="Shipping.Street1"><input name="Shipping.Zip">`,
+		`</fieldset>`,
+		`<fieldset data-name="Contact" data-depth="1"><legend>Contact</legend>`,
+		`<input name="Contact.Email">`,
+		`<fieldset data-name="Contact.Home" data-depth="2"><legend>Home address</legend>`,
+		`<input name="Contact.Home.Street1"><input name="Contact.Home.Zip">`,
+		`</fieldset>`,
+		`</fieldset>`,
+		`<fieldset data-name="Lines.0" data-depth="1"><legend>Line</legend>`,
+		`<input name="Lines.0.Street1"><input name="Lines.0.Zip">`,
+		`</fieldset>`,
+		`<fieldset data-name="Lines.1" data-depth="1"><legend>Line</legend>`,
+		`<input name="Lines.1.Street1"><input name="Lines.1.Zip">`,
+		`</fieldset>`,
+		`<fieldset data-name="Places.home" data-depth="1"><legend>home</legend>`,
+		`<input name="Places.home.Street1"><input name="Places.home.Zip">`,
+		`</fieldset>`,
+		`<fieldset data-name="Places.work" data-depth="1"><legend>work</legend>`,
+		`<input name="Places.work.Street1"><input name="Places.work.Zip">`,
+		`</fieldset>`,
+		`<input name="Notes">`,
+	}, ""))
+	if got != want {
+		t.Errorf("Builder.Inputs() = %v, want %v", got, want)
+	}
+}
+
+func TestBuilder_Inputs_mapGroups(t *testing.T) {
+	type address struct {
+		Zip string
+	}
+	b := &Builder{
+		InputTemplate: template.Must(template.New("").Parse(`<input name="{{.Name}}">`)),
+		GroupTemplate: template.Must(template.New("").Parse(`<fieldset><legend>{{.Label}}</legend>{{.Children}}</fieldset>`)),
+		MapKeyLabel:   strings.ToUpper,
+	}
+	got, err := b.Inputs(map[string]address{"home": {}, "work": {}})
+	if err != nil {
+		t.Fatalf("Builder.Inputs() err = %v, want %v", err, nil)
+	}
+	want := template.HTML(`<fieldset><legend>HOME</legend><input name="home.Zip"></fieldset>` +
+		`<fieldset><legend>WORK</legend><input name="work.Zip"></fieldset>`)
+	if got != want {
+		t.Errorf("Builder.Inputs() = %v, want %v", got, want)
+	}
+}
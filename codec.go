@@ -0,0 +1,264 @@
+package form
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Codec is used to convert values of a particular type to and from the
+// strings used in HTML forms. Format is used when rendering a field, and
+// the resulting string is provided to the InputTemplate as the field's
+// Formatted value. Parse is used by Decode to convert a submitted value
+// back into the type, and the returned reflect.Value must be assignable or
+// convertible to that type.
+//
+// Codecs are registered per type via the Builder's Codecs field, eg:
+//
+//   fb := form.Builder{
+//     Codecs: map[reflect.Type]form.Codec{
+//       reflect.TypeOf(Cents(0)): centsCodec{},
+//     },
+//   }
+//
+// Types without a registered Codec use a default, which handles the basic
+// kinds (strings, bools, and numbers), []byte, time.Time, the sql.Null*
+// types, and any type implementing encoding.TextMarshaler or
+// encoding.TextUnmarshaler.
+type Codec interface {
+	Format(rv reflect.Value) string
+	Parse(str string) (reflect.Value, error)
+}
+
+// codec returns the Codec that should be used for values of type t, or nil
+// if there isn't one. inputType is used to pick the format for times.
+func (b *Builder) codec(t reflect.Type, inputType string) Codec {
+	if c, ok := b.Codecs[t]; ok {
+		return c
+	}
+	switch {
+	case t == timeReflectType:
+		layout, ok := timeLayouts[inputType]
+		if !ok {
+			layout = time.RFC3339
+		}
+		return TimeCodec{Layout: layout}
+	case isSQLNull(t):
+		inner := b.codec(t.Field(0).Type, inputType)
+		if inner == nil {
+			return nil
+		}
+		return nullCodec{t: t, inner: inner}
+	case t.Implements(textMarshalerType),
+		reflect.PtrTo(t).Implements(textMarshalerType),
+		reflect.PtrTo(t).Implements(textUnmarshalerType):
+		return textCodec{t: t}
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return scalarCodec{t: t}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return scalarCodec{t: t}
+		}
+	}
+	return nil
+}
+
+// format returns the formatted value of rv. Nil pointers are formatted as
+// an empty string, and types without a Codec fall back to fmt.Sprint.
+func (b *Builder) format(rv reflect.Value, inputType string) string {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return ""
+	}
+	if c := b.codec(rv.Type(), inputType); c != nil {
+		return c.Format(rv)
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
+// timeLayouts maps input types to the layout browsers expect for them.
+var timeLayouts = map[string]string{
+	"date":           "2006-01-02",
+	"datetime-local": "2006-01-02T15:04",
+	"month":          "2006-01",
+	"time":           "15:04",
+}
+
+// TimeCodec is the Codec used for time.Time values. By default the Layout
+// is picked based on the field's input type, eg a date input uses
+// 2006-01-02, but a TimeCodec can be registered to use another layout.
+//
+// When parsing, the Layout is tried first followed by RFC3339 and each of
+// the layouts used by the date, datetime-local, month, and time inputs.
+// Zero times are formatted as an empty string.
+type TimeCodec struct {
+	Layout string
+}
+
+// Format formats rv, which must be a time.Time, using the Layout.
+func (c TimeCodec) Format(rv reflect.Value) string {
+	t := rv.Interface().(time.Time)
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(c.Layout)
+}
+
+// Parse parses str into a time.Time.
+func (c TimeCodec) Parse(str string) (reflect.Value, error) {
+	t, err := time.Parse(c.Layout, str)
+	if err == nil {
+		return reflect.ValueOf(t), nil
+	}
+	// datetime-local inputs may also include seconds.
+	layouts := []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02T15:04:05", "2006-01-02", "2006-01", "15:04"}
+	for _, layout := range layouts {
+		if t, lerr := time.Parse(layout, str); lerr == nil {
+			return reflect.ValueOf(t), nil
+		}
+	}
+	return reflect.Value{}, err
+}
+
+// scalarCodec handles strings, bools, and numbers, along with []byte which
+// is treated like a string.
+type scalarCodec struct {
+	t reflect.Type
+}
+
+func (c scalarCodec) Format(rv reflect.Value) string {
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, rv.Type().Bits())
+	case reflect.Slice:
+		return string(rv.Bytes())
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
+func (c scalarCodec) Parse(str string) (reflect.Value, error) {
+	rv := reflect.New(c.t).Elem()
+	switch c.t.Kind() {
+	case reflect.String:
+		rv.SetString(str)
+	case reflect.Bool:
+		// Checkboxes without a value attribute are submitted as "on"
+		if str == "on" {
+			rv.SetBool(true)
+			break
+		}
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(str, 10, c.t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(str, 10, c.t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(str, c.t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		rv.SetFloat(n)
+	case reflect.Slice:
+		rv.SetBytes([]byte(str))
+	default:
+		return reflect.Value{}, fmt.Errorf("form: unsupported kind %v", c.t.Kind())
+	}
+	return rv, nil
+}
+
+// nullCodec handles the sql.Null* types by using the codec of the wrapped
+// value. Invalid values are formatted as an empty string, and empty
+// strings are parsed as invalid values.
+type nullCodec struct {
+	t     reflect.Type
+	inner Codec
+}
+
+func (c nullCodec) Format(rv reflect.Value) string {
+	if !rv.Field(1).Bool() {
+		return ""
+	}
+	return c.inner.Format(rv.Field(0))
+}
+
+func (c nullCodec) Parse(str string) (reflect.Value, error) {
+	rv := reflect.New(c.t).Elem()
+	if str == "" {
+		return rv, nil
+	}
+	v, err := c.inner.Parse(str)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	rv.Field(0).Set(v)
+	rv.Field(1).SetBool(true)
+	return rv, nil
+}
+
+// textCodec handles types implementing encoding.TextMarshaler and
+// encoding.TextUnmarshaler. If a type only implements one of the two, the
+// scalarCodec is used for the other when possible.
+type textCodec struct {
+	t reflect.Type
+}
+
+func (c textCodec) Format(rv reflect.Value) string {
+	tm, ok := rv.Interface().(encoding.TextMarshaler)
+	if !ok && reflect.PtrTo(c.t).Implements(textMarshalerType) {
+		ptr := reflect.New(c.t)
+		ptr.Elem().Set(rv)
+		tm, ok = ptr.Interface().(encoding.TextMarshaler)
+	}
+	if !ok {
+		return scalarCodec{t: c.t}.Format(rv)
+	}
+	text, err := tm.MarshalText()
+	if err != nil {
+		return ""
+	}
+	return string(text)
+}
+
+func (c textCodec) Parse(str string) (reflect.Value, error) {
+	ptr := reflect.New(c.t)
+	tu, ok := ptr.Interface().(encoding.TextUnmarshaler)
+	if !ok {
+		return scalarCodec{t: c.t}.Parse(str)
+	}
+	if err := tu.UnmarshalText([]byte(str)); err != nil {
+		return reflect.Value{}, err
+	}
+	return ptr.Elem(), nil
+}
@@ -0,0 +1,203 @@
+package form
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testCents int64
+
+type testCentsCodec struct{}
+
+func (testCentsCodec) Format(rv reflect.Value) string {
+	c := rv.Int()
+	return fmt.Sprintf("$%d.%02d", c/100, c%100)
+}
+
+func (testCentsCodec) Parse(str string) (reflect.Value, error) {
+	f, err := strconv.ParseFloat(strings.TrimPrefix(str, "$"), 64)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	// Returning an int64 rather than testCents is intentional, as the
+	// Builder should convert it for us.
+	return reflect.ValueOf(int64(f*100 + 0.5)), nil
+}
+
+type testLevel int
+
+func (l testLevel) MarshalText() ([]byte, error) {
+	return []byte([]string{"low", "high"}[l]), nil
+}
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 0
+	case "high":
+		*l = 1
+	default:
+		return fmt.Errorf("invalid level %q", text)
+	}
+	return nil
+}
+
+type codecForm struct {
+	Price    testCents
+	Level    testLevel
+	Birthday time.Time
+	Ratio    float64
+	Admin    bool
+	Count    *int
+}
+
+func TestBuilder_Codecs(t *testing.T) {
+	b := &Builder{
+		Codecs: map[reflect.Type]Codec{
+			reflect.TypeOf(testCents(0)): testCentsCodec{},
+			reflect.TypeOf(time.Time{}):  TimeCodec{Layout: "01/02/2006"},
+		},
+	}
+	v := codecForm{
+		Price:    1234,
+		Level:    1,
+		Birthday: time.Date(2019, 3, 14, 0, 0, 0, 0, time.UTC),
+		Ratio:    0.25,
+		Admin:    true,
+	}
+	got := map[string]string{}
+	fields, err := b.fields(v)
+	if err != nil {
+		t.Fatalf("fields() err = %v, want %v", err, nil)
+	}
+	for _, f := range fields {
+		got[f.Name] = f.Formatted
+	}
+	want := map[string]string{
+		"Price":    "$12.34",
+		"Level":    "high",
+		"Birthday": "03/14/2019",
+		"Ratio":    "0.25",
+		"Admin":    "true",
+		"Count":    "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields() Formatted = %v, want %v", got, want)
+	}
+
+	values := url.Values{}
+	for k, v := range want {
+		if v != "" {
+			values.Set(k, v)
+		}
+	}
+	var decoded codecForm
+	if err := b.Decode(&decoded, values); err != nil {
+		t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+	}
+	if !reflect.DeepEqual(decoded, v) {
+		t.Errorf("Builder.Decode() = %+v, want %+v", decoded, v)
+	}
+
+	err = b.Decode(&decoded, url.Values{"Level": {"medium"}})
+	if err == nil {
+		t.Errorf("Builder.Decode() err = nil, want an error")
+	}
+}
+
+func TestTimeCodec_Parse(t *testing.T) {
+	c := TimeCodec{Layout: "2006-01-02"}
+	tests := map[string]time.Time{
+		"2019-03-14":           time.Date(2019, 3, 14, 0, 0, 0, 0, time.UTC),
+		"2019-03-14T15:09":     time.Date(2019, 3, 14, 15, 9, 0, 0, time.UTC),
+		"2019-03-14T15:09:26":  time.Date(2019, 3, 14, 15, 9, 26, 0, time.UTC),
+		"2019-03-14T15:09:26Z": time.Date(2019, 3, 14, 15, 9, 26, 0, time.UTC),
+		"2019-03":              time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for str, want := range tests {
+		got, err := c.Parse(str)
+		if err != nil {
+			t.Errorf("TimeCodec.Parse(%q) err = %v, want %v", str, err, nil)
+			continue
+		}
+		if !got.Interface().(time.Time).Equal(want) {
+			t.Errorf("TimeCodec.Parse(%q) = %v, want %v", str, got, want)
+		}
+	}
+	if _, err := c.Parse("tomorrow"); err == nil {
+		t.Errorf("TimeCodec.Parse(%q) err = nil, want an error", "tomorrow")
+	}
+}
+
+type testPrice struct {
+	cents int64
+}
+
+type testPriceCodec struct{}
+
+func (testPriceCodec) Format(rv reflect.Value) string {
+	return testCentsCodec{}.Format(reflect.ValueOf(rv.Interface().(testPrice).cents))
+}
+
+func (testPriceCodec) Parse(str string) (reflect.Value, error) {
+	v, err := testCentsCodec{}.Parse(str)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(testPrice{cents: v.Int()}), nil
+}
+
+func TestBuilder_Codecs_structs(t *testing.T) {
+	type order struct {
+		Total    testPrice
+		Discount *testPrice
+		Lines    []testPrice
+		Fees     map[string]testPrice
+	}
+	b := &Builder{
+		Codecs: map[reflect.Type]Codec{
+			reflect.TypeOf(testPrice{}): testPriceCodec{},
+		},
+	}
+	v := order{
+		Total:    testPrice{1250},
+		Discount: &testPrice{100},
+		Lines:    []testPrice{{1000}, {250}},
+		Fees:     map[string]testPrice{"shipping": {499}},
+	}
+	fields, err := b.fields(v)
+	if err != nil {
+		t.Fatalf("fields() err = %v, want %v", err, nil)
+	}
+	got := map[string]string{}
+	for _, f := range fields {
+		got[f.Name] = f.Formatted
+	}
+	want := map[string]string{
+		"Total":         "$12.50",
+		"Discount":      "$1.00",
+		"Lines.0":       "$10.00",
+		"Lines.1":       "$2.50",
+		"Fees.shipping": "$4.99",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields() Formatted = %v, want %v", got, want)
+	}
+
+	values := url.Values{}
+	for k, v := range want {
+		values.Set(k, v)
+	}
+	var decoded order
+	if err := b.Decode(&decoded, values); err != nil {
+		t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+	}
+	if !reflect.DeepEqual(decoded, v) {
+		t.Errorf("Builder.Decode() = %+v, want %+v", decoded, v)
+	}
+}
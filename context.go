@@ -0,0 +1,131 @@
+package form
+
+import (
+	"context"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+type contextKey int
+
+const localeKey contextKey = iota
+
+// WithLocale returns a copy of ctx that carries the provided locale. The
+// Context variants of the Builder's methods use it in place of the
+// Builder's Locale, which makes it easy to render in the language of the
+// current request. Eg, in middleware:
+//
+//   ctx := form.WithLocale(r.Context(), r.Header.Get("Accept-Language"))
+//   next.ServeHTTP(w, r.WithContext(ctx))
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey, locale)
+}
+
+// LocaleFromContext returns the locale stored in ctx by WithLocale.
+func LocaleFromContext(ctx context.Context) (string, bool) {
+	locale, ok := ctx.Value(localeKey).(string)
+	return locale, ok
+}
+
+// ContextTranslator is a Translator that also needs the context of the
+// request being rendered, eg to look up a user's own wording. When the
+// Builder's Translator implements it, TranslateContext is used instead of
+// Translate.
+type ContextTranslator interface {
+	Translator
+	TranslateContext(ctx context.Context, locale, key string, args ...interface{}) string
+}
+
+// InputsContext is the same as Inputs, but ctx is provided to the
+// Builder's hooks, like the FieldFilter and the Translator, so that
+// per-request state can affect rendering. The locale stored in ctx by
+// WithLocale, if any, is used in place of the Builder's Locale.
+func (b *Builder) InputsContext(ctx context.Context, v interface{}, errs ...error) (template.HTML, error) {
+	return b.withContext(ctx).inputs(v, errs)
+}
+
+// FieldsContext is the same as Fields, but ctx is provided to the
+// Builder's hooks. See InputsContext for more info.
+func (b *Builder) FieldsContext(ctx context.Context, v interface{}) ([]Field, error) {
+	return b.withContext(ctx).filteredFields(v)
+}
+
+// InputContext is the same as Input, but ctx is used to translate error
+// messages. See InputsContext for more info.
+func (b *Builder) InputContext(ctx context.Context, f Field, errs ...error) (template.HTML, error) {
+	return b.withContext(ctx).input(f, errs)
+}
+
+// DecodeContext is the same as Decode, but ctx is provided to the
+// Builder's hooks. This should be used with the same context as the form
+// was rendered with, so that the FieldFilter leaves out the same fields.
+func (b *Builder) DecodeContext(ctx context.Context, dst interface{}, values url.Values) error {
+	return b.withContext(ctx).Decode(dst, values)
+}
+
+// ValidateContext is the same as Validate, but ctx is provided to the
+// Builder's hooks. See DecodeContext for more info.
+func (b *Builder) ValidateContext(ctx context.Context, v interface{}) error {
+	return b.withContext(ctx).Validate(v)
+}
+
+var tokenTpl = template.Must(template.New("").Parse(`<input type="hidden" name="{{.Name}}" value="{{.Value}}">`))
+
+// TokenInput renders a hidden input with the name and value returned by
+// the Builder's TokenFunc, eg for a CSRF token. Nothing is rendered when
+// there isn't a TokenFunc or it returns an empty name.
+func (b *Builder) TokenInput(ctx context.Context) (template.HTML, error) {
+	if b.TokenFunc == nil {
+		return "", nil
+	}
+	name, value, err := b.TokenFunc(b.withContext(ctx).context())
+	if err != nil || name == "" {
+		return "", err
+	}
+	var sb strings.Builder
+	err = tokenTpl.Execute(&sb, struct{ Name, Value string }{name, value})
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(sb.String()), nil
+}
+
+// withContext returns a copy of the Builder that uses ctx for its hooks,
+// along with the locale stored in ctx. The original Builder is never
+// modified, so it can be shared by concurrent requests.
+func (b *Builder) withContext(ctx context.Context) *Builder {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cb := *b
+	cb.ctx = ctx
+	if locale, ok := LocaleFromContext(ctx); ok {
+		cb.Locale = locale
+	}
+	return &cb
+}
+
+// context returns the context the Builder is rendering with, which is
+// context.Background() unless a Context method was used.
+func (b *Builder) context() context.Context {
+	if b.ctx == nil {
+		return context.Background()
+	}
+	return b.ctx
+}
+
+// filteredFields returns the fields of v that the FieldFilter allows.
+func (b *Builder) filteredFields(v interface{}) ([]Field, error) {
+	fields, err := b.fields(v)
+	if err != nil || b.FieldFilter == nil {
+		return fields, err
+	}
+	ret := fields[:0]
+	for _, f := range fields {
+		if b.FieldFilter(b.context(), f) {
+			ret = append(ret, f)
+		}
+	}
+	return ret, nil
+}
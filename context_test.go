@@ -0,0 +1,264 @@
+package form
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testRoleKey struct{}
+
+type testContextTranslator struct {
+	MapTranslator
+}
+
+func (t testContextTranslator) TranslateContext(ctx context.Context, locale, key string, args ...interface{}) string {
+	msg := t.Translate(locale, key, args...)
+	if role, ok := ctx.Value(testRoleKey{}).(string); ok {
+		msg += " (" + role + ")"
+	}
+	return msg
+}
+
+func TestBuilder_InputsContext(t *testing.T) {
+	tpl := template.Must(template.New("").Funcs(FuncMap()).Parse(`<label>{{.Label}}</label>{{range errors}}<p>{{.}}</p>{{end}}`))
+	b := &Builder{
+		InputTemplate: tpl,
+		Locale:        "en",
+		Translator: testContextTranslator{MapTranslator{
+			"en": {"name": "Name", "blank": "is blank"},
+			"fr": {"name": "Nom", "blank": "est vide"},
+		}},
+		FieldFilter: func(ctx context.Context, f Field) bool {
+			role, _ := ctx.Value(testRoleKey{}).(string)
+			return f.Name != "Admin" || role == "admin"
+		},
+	}
+	arg := struct {
+		Name  string `form:"label=@name"`
+		Admin bool
+	}{}
+	errs := []error{testFieldError{"Name", "@blank"}}
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{
+			name: "background",
+			ctx:  context.Background(),
+			want: `<label>Name</label><p>is blank</p>`,
+		}, {
+			name: "nil",
+			ctx:  nil,
+			want: `<label>Name</label><p>is blank</p>`,
+		}, {
+			name: "locale",
+			ctx:  WithLocale(context.Background(), "fr"),
+			want: `<label>Nom</label><p>est vide</p>`,
+		}, {
+			name: "admin",
+			ctx:  context.WithValue(context.Background(), testRoleKey{}, "admin"),
+			want: `<label>Name (admin)</label><p>is blank (admin)</p><label>Admin</label>`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := b.InputsContext(tc.ctx, arg, errs...)
+			if err != nil {
+				t.Fatalf("Builder.InputsContext() err = %v, want %v", err, nil)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Builder.InputsContext() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+	if b.Locale != "en" || b.ctx != nil {
+		t.Errorf("Builder was modified by InputsContext")
+	}
+}
+
+func TestBuilder_TokenInput(t *testing.T) {
+	ctx := context.WithValue(context.Background(), testRoleKey{}, `a"b`)
+	tests := []struct {
+		name    string
+		fn      func(ctx context.Context) (string, string, error)
+		want    template.HTML
+		wantErr bool
+	}{
+		{
+			name: "nil",
+			fn:   nil,
+			want: "",
+		}, {
+			name: "token",
+			fn: func(ctx context.Context) (string, string, error) {
+				return "csrf", ctx.Value(testRoleKey{}).(string), nil
+			},
+			want: `<input type="hidden" name="csrf" value="a&#34;b">`,
+		}, {
+			name: "empty name",
+			fn: func(ctx context.Context) (string, string, error) {
+				return "", "abc", nil
+			},
+			want: "",
+		}, {
+			name: "error",
+			fn: func(ctx context.Context) (string, string, error) {
+				return "", "", errors.New("no session")
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &Builder{TokenFunc: tc.fn}
+			got, err := b.TokenInput(ctx)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Builder.TokenInput() err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("Builder.TokenInput() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_FuncMap_context(t *testing.T) {
+	b := &Builder{
+		InputTemplate: template.Must(template.New("").Parse(`<input name="{{.Name}}">`)),
+		FieldFilter: func(ctx context.Context, f Field) bool {
+			return ctx.Value(testRoleKey{}) != nil || f.Name != "Secret"
+		},
+		TokenFunc: func(ctx context.Context) (string, string, error) {
+			return "csrf", "abc", nil
+		},
+	}
+	page := template.Must(template.New("").Funcs(b.FuncMap()).Parse(strings.TrimSpace(`
+{{token_for .Ctx}}{{inputs_and_errors_for_context .Ctx .Form nil}}|{{range fields_for_context .Ctx .Form}}{{input_for_context $.Ctx .}}{{end}}
+	`)))
+	form := struct{ Name, Secret string }{}
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{
+			name: "filtered",
+			ctx:  context.Background(),
+			want: `<input type="hidden" name="csrf" value="abc"><input name="Name">|<input name="Name">`,
+		}, {
+			name: "unfiltered",
+			ctx:  context.WithValue(context.Background(), testRoleKey{}, "admin"),
+			want: `<input type="hidden" name="csrf" value="abc"><input name="Name"><input name="Secret">|<input name="Name"><input name="Secret">`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var sb strings.Builder
+			err := page.Execute(&sb, map[string]interface{}{"Ctx": tc.ctx, "Form": form})
+			if err != nil {
+				t.Fatalf("Execute() err = %v, want %v", err, nil)
+			}
+			if got := sb.String(); got != tc.want {
+				t.Errorf("Execute() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_DecodeContext(t *testing.T) {
+	type line struct {
+		Item  string
+		Price int
+	}
+	type account struct {
+		Name  string
+		Admin bool
+		Roles []string
+		Prefs map[string]string
+		Lines []line
+	}
+	b := &Builder{
+		FieldFilter: func(ctx context.Context, f Field) bool {
+			if ctx.Value(testRoleKey{}) != nil {
+				return true
+			}
+			switch {
+			case f.Name == "Admin", strings.HasPrefix(f.Name, "Roles."),
+				f.Name == "Prefs.plan", f.Label == "Price":
+				return false
+			}
+			return true
+		},
+	}
+	values := map[string][]string{
+		"Name":          {"Jon"},
+		"Admin":         {"true"},
+		"Roles":         {"owner"},
+		"Roles.3":       {"owner"},
+		"Prefs.theme":   {"dark"},
+		"Prefs.plan":    {"free"},
+		"Lines.5.Item":  {"Widget"},
+		"Lines.5.Price": {"7"},
+	}
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want account
+	}{
+		{
+			name: "filtered",
+			ctx:  context.Background(),
+			want: account{
+				Name:  "Jon",
+				Prefs: map[string]string{"theme": "dark"},
+				Lines: []line{{Item: "Widget"}},
+			},
+		}, {
+			name: "unfiltered",
+			ctx:  context.WithValue(context.Background(), testRoleKey{}, "admin"),
+			want: account{
+				Name:  "Jon",
+				Admin: true,
+				Roles: []string{"owner"},
+				Prefs: map[string]string{"theme": "dark", "plan": "free"},
+				Lines: []line{{Item: "Widget", Price: 7}},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got account
+			if err := b.DecodeContext(tc.ctx, &got, values); err != nil {
+				t.Fatalf("Builder.DecodeContext() err = %v, want %v", err, nil)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Builder.DecodeContext() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_ValidateContext(t *testing.T) {
+	b := &Builder{
+		FieldFilter: func(ctx context.Context, f Field) bool {
+			return ctx.Value(testRoleKey{}) != nil || f.Name != "Secret"
+		},
+	}
+	arg := struct {
+		Name   string `form:"required"`
+		Secret string `form:"required"`
+	}{Name: "Jon"}
+	if err := b.ValidateContext(context.Background(), arg); err != nil {
+		t.Errorf("Builder.ValidateContext() err = %v, want %v", err, nil)
+	}
+	ctx := context.WithValue(context.Background(), testRoleKey{}, "admin")
+	var errs Errors
+	if err := b.ValidateContext(ctx, arg); !errors.As(err, &errs) || !errs.Has("Secret") {
+		t.Errorf("Builder.ValidateContext() err = %v, want an error for Secret", err)
+	}
+}
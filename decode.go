@@ -0,0 +1,541 @@
+package form
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Decode will parse the provided url.Values into dst, which must be a
+// non-nil pointer to a struct or a map with string keys. It walks the
+// struct using the same rules that the Inputs method does when it builds
+// each field, so nested structs are expected to use names joined using
+// the Builder's Naming (eg Address.Street1 by default), nil pointers are
+// allocated as needed, fields tagged with `form:"-"` are ignored, and
+// fields with a custom name (`form:"name=..."`) are read from that name.
+// This means anything rendered by the Builder can be decoded back into
+// the same type.
+//
+// A basic usage looks something like this:
+//
+//   r.ParseForm()
+//   var c customer
+//   err := fb.Decode(&c, r.PostForm)
+//
+// Fields that are not present in values are left untouched, unless the
+// Builder's UncheckMissing is true, in which case missing checkboxes are
+// set to false. When a field has multiple values, the last one is used.
+// Pointer fields submitted with an empty value are set to nil, which makes
+// it possible to tell a blank optional field apart from one set to its
+// zero value.
+//
+// Fields left out by the Builder's FieldFilter are never set, even if a
+// value was submitted for them, so a field the current user can't see
+// can't be changed by them either. Use DecodeContext to provide the
+// filter with a context.
+//
+// If any values can't be converted into their field's type, Decode will
+// still decode every other field and then return an Errors value with a
+// *DecodeError for each failure. DecodeError implements the FieldErrorer
+// interface, so these can be passed directly into inputs_and_errors_for
+// to render the form again along with the user's input.
+func (b *Builder) Decode(dst interface{}, values url.Values) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidValueError{Reason: "Decode requires a non-nil pointer"}
+	}
+	rv = rv.Elem()
+	var errs Errors
+	switch {
+	case isMap(rv.Type()):
+		b.decodeMap(reflect.StructField{}, rv, map[string]string{}, values, &errs, nil, nil)
+	case rv.Kind() == reflect.Struct:
+		b.decode(rv, values, &errs, nil, nil)
+	default:
+		return &UnsupportedTypeError{Type: rv.Type()}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// decode sets each field in rv that has a value present in values. It
+// returns true if any field was set, which is used to determine whether a
+// nil pointer to a nested struct should be allocated.
+//
+// parents are the types of every struct and map that lead up to rv, just
+// like they are when walking fields.
+func (b *Builder) decode(rv reflect.Value, values url.Values, errs *Errors, names []string, parents []reflect.Type) bool {
+	if len(parents) >= b.maxDepth() {
+		errs.add(&DepthError{Path: b.joinNames(names), MaxDepth: b.maxDepth()})
+		return false
+	}
+	parents = append(parents[:len(parents):len(parents)], rv.Type())
+	var set bool
+	for _, sf := range b.structFields(rv.Type()) {
+		rf, ok := fieldByIndex(rv, sf.Index)
+		if !ok {
+			// This field is promoted from a nil embedded pointer, so we
+			// decode into a new value and only allocate the pointer if
+			// something was actually set.
+			nv := reflect.New(sf.Type).Elem()
+			if b.decodeField(sf, nv, values, errs, names, parents) && setFieldByIndex(rv, sf.Index, nv) {
+				set = true
+			}
+			continue
+		}
+		set = b.decodeField(sf, rf, values, errs, names, parents) || set
+	}
+	return set
+}
+
+// decodeField decodes a single field of a struct into rf. It returns true
+// if rf was set.
+func (b *Builder) decodeField(sf structField, rf reflect.Value, values url.Values, errs *Errors, names []string, parents []reflect.Type) bool {
+	tags := sf.tags
+
+	// Nested structs are handled the same way as in fields, which means
+	// the struct's name (or its name tag) is used as a prefix. Nil
+	// pointers are only allocated if one of the nested fields was
+	// actually provided.
+	if sf.Type.Kind() == reflect.Ptr && sf.Type.Elem().Kind() == reflect.Struct && !b.isLeaf(sf.Type.Elem()) {
+		name, _ := b.containerName(sf.StructField, tags)
+		if !rf.IsNil() {
+			return b.decode(rf.Elem(), values, errs, append(names, name), parents)
+		}
+		// Embedded pointers to unexported structs can't be allocated.
+		if !rf.CanSet() {
+			return false
+		}
+		// A pointer to a type we are already inside of, like a Parent
+		// *Category field in a Category, would have us allocating new
+		// values forever, so it is only followed if a value was
+		// submitted for one of its fields.
+		if hasType(parents, sf.Type.Elem()) && !hasPrefix(values, b.joinNames(append(names, name))) {
+			return false
+		}
+		nv := reflect.New(sf.Type.Elem())
+		if !b.decode(nv.Elem(), values, errs, append(names, name), parents) {
+			return false
+		}
+		rf.Set(nv)
+		return true
+	}
+	if rf.Kind() == reflect.Struct && !b.isLeaf(rf.Type()) {
+		name, _ := b.containerName(sf.StructField, tags)
+		return b.decode(rf, values, errs, append(names, name), parents)
+	}
+	if isRepeated(sf.Type) {
+		name, etags := b.containerName(sf.StructField, tags)
+		return b.decodeRepeated(sf.StructField, rf, etags, values, errs, append(names, name), parents)
+	}
+	if isMap(sf.Type) {
+		name, etags := b.containerName(sf.StructField, tags)
+		return b.decodeMap(sf.StructField, rf, etags, values, errs, append(names, name), parents)
+	}
+	name := b.joinNames(append(names, b.fieldName(sf.StructField)))
+	if v, ok := tags["name"]; ok {
+		name = v
+	}
+	if b.unsupportedMap(sf.Type) {
+		errs.add(&UnsupportedTypeError{Path: name, Type: sf.Type})
+		return false
+	}
+	if !b.allowed(sf.StructField, rf, tags, append(names, b.fieldName(sf.StructField))) {
+		return false
+	}
+	vals, ok := values[name]
+	if !ok || len(vals) == 0 {
+		// Browsers don't submit unchecked checkboxes at all, so with
+		// UncheckMissing a missing checkbox means false. This doesn't
+		// count as setting the field, as it shouldn't cause a nil pointer
+		// to be allocated.
+		if b.UncheckMissing && rf.CanSet() && b.isCheckbox(sf) {
+			b.decodeInto(rf, name, "false", errs)
+		}
+		return false
+	}
+	return b.decodeInto(rf, name, vals[len(vals)-1], errs)
+}
+
+// isCheckbox returns true if sf is a bool (or *bool, sql.NullBool, etc)
+// rendered as a checkbox.
+func (b *Builder) isCheckbox(sf structField) bool {
+	if valueType(sf.Type).Kind() != reflect.Bool {
+		return false
+	}
+	if v, ok := sf.tags["type"]; ok {
+		return v == "checkbox"
+	}
+	return b.inputType(sf.StructField) == "checkbox"
+}
+
+// decodeRepeated decodes a slice or array field. Elements are found by
+// looking for values with the field's name followed by an index, eg
+// Items.0 or Items.0.Name, and they are decoded in the order of their
+// indexes. Gaps in the indexes are ignored for slices, which makes it
+// easier to remove rows in the browser, but arrays always use the index
+// as the position of the element.
+//
+// Slices of non-struct types can also be submitted as multiple values
+// with the slice's name, like a <select multiple> would, in which case
+// each value is an element.
+//
+// names should include the name of the slice field itself, and sf and
+// tags are used to build the field for each non-struct element when the
+// Builder has a FieldFilter, just like they are in repeatedFields.
+func (b *Builder) decodeRepeated(sf reflect.StructField, rf reflect.Value, tags map[string]string, values url.Values, errs *Errors, names []string, parents []reflect.Type) bool {
+	prefix := b.joinNames(names)
+
+	target := rf
+	if rf.Kind() == reflect.Ptr {
+		target = reflect.New(rf.Type().Elem()).Elem()
+	}
+	et := target.Type().Elem()
+	isStruct := elemType(et).Kind() == reflect.Struct && !b.isLeaf(elemType(et))
+	if b.unsupportedMap(et) {
+		errs.add(&UnsupportedTypeError{Path: prefix, Type: et})
+		return false
+	}
+	esf := sf
+	esf.Type = et
+
+	// decodeElem decodes the element at index into ev, which is settable.
+	decodeElem := func(ev reflect.Value, index int) bool {
+		path := append(names, b.indexName(index))
+		if !isStruct {
+			vals := values[b.joinNames(path)]
+			if len(vals) == 0 || !b.allowed(esf, ev, tags, path) {
+				return false
+			}
+			return b.decodeInto(ev, b.joinNames(path), vals[len(vals)-1], errs)
+		}
+		if ev.Kind() == reflect.Ptr {
+			nv := reflect.New(et.Elem())
+			if !b.decode(nv.Elem(), values, errs, path, parents) {
+				return false
+			}
+			ev.Set(nv)
+			return true
+		}
+		return b.decode(ev, values, errs, path, parents)
+	}
+
+	switch {
+	case !isStruct && target.Kind() == reflect.Slice && len(values[prefix]) > 0:
+		vals := values[prefix]
+		slice := reflect.MakeSlice(target.Type(), 0, len(vals))
+		for i, str := range vals {
+			ev := reflect.New(et).Elem()
+			if !b.allowed(esf, ev, tags, append(names, b.indexName(i))) {
+				continue
+			}
+			b.decodeInto(ev, prefix, str, errs)
+			slice = reflect.Append(slice, ev)
+		}
+		if slice.Len() == 0 {
+			return false
+		}
+		target.Set(slice)
+	case target.Kind() == reflect.Slice:
+		indexes := b.indexes(values, prefix)
+		if len(indexes) == 0 {
+			return false
+		}
+		slice := reflect.MakeSlice(target.Type(), len(indexes), len(indexes))
+		for i, index := range indexes {
+			decodeElem(slice.Index(i), index)
+		}
+		target.Set(slice)
+	default:
+		var set bool
+		for _, index := range b.indexes(values, prefix) {
+			if index < target.Len() {
+				set = decodeElem(target.Index(index), index) || set
+			}
+		}
+		if !set {
+			return false
+		}
+	}
+	if rf.Kind() == reflect.Ptr {
+		rf.Set(target.Addr())
+	}
+	return true
+}
+
+// decodeMap decodes a map with string keys. Entries are found by looking
+// for values with the map's name followed by the key, eg Settings.theme
+// (or Settings[theme] with BracketNaming). For maps with struct values
+// the key ends where the struct's field names begin, eg
+// Addresses.home.Street1, but for other values the entire remainder of
+// the name is the key. The map is allocated if it is nil, and keys that
+// weren't submitted are left untouched.
+//
+// names should include the name of the map field itself, unless the map
+// is the top level value being decoded, and sf and tags are used to build
+// the field for each non-struct entry when the Builder has a FieldFilter,
+// just like they are in mapFields.
+func (b *Builder) decodeMap(sf reflect.StructField, rf reflect.Value, tags map[string]string, values url.Values, errs *Errors, names []string, parents []reflect.Type) bool {
+	prefix := b.joinNames(names)
+
+	target := rf
+	if rf.Kind() == reflect.Ptr {
+		target = reflect.New(rf.Type().Elem()).Elem()
+		if !rf.IsNil() {
+			target.Set(rf.Elem())
+		}
+	}
+	mt := target.Type()
+	vt := mt.Elem()
+	isStruct := elemType(vt).Kind() == reflect.Struct && !b.isLeaf(elemType(vt))
+	if b.unsupportedMap(vt) {
+		errs.add(&UnsupportedTypeError{Path: prefix, Type: vt})
+		return false
+	}
+
+	// Names without any values are skipped so that an empty map isn't
+	// allocated for them.
+	keys := make(map[string]bool)
+	for name, vals := range values {
+		if len(vals) == 0 {
+			continue
+		}
+		if key, ok := b.nextName(name, prefix, !isStruct); ok {
+			keys[key] = true
+		}
+	}
+	if len(keys) == 0 {
+		return false
+	}
+
+	if target.IsNil() {
+		target.Set(reflect.MakeMap(mt))
+	}
+	var set bool
+	for key := range keys {
+		kv := reflect.ValueOf(key).Convert(mt.Key())
+		path := append(names, key)
+		ev := reflect.New(vt).Elem()
+		if existing := target.MapIndex(kv); existing.IsValid() {
+			ev.Set(existing)
+		}
+		var ok bool
+		switch {
+		case isStruct && ev.Kind() == reflect.Ptr:
+			nv := reflect.New(vt.Elem())
+			if !ev.IsNil() {
+				nv.Elem().Set(ev.Elem())
+			}
+			if ok = b.decode(nv.Elem(), values, errs, path, parents); ok {
+				ev.Set(nv)
+			}
+		case isStruct:
+			ok = b.decode(ev, values, errs, path, parents)
+		default:
+			name := b.joinNames(path)
+			vals := values[name]
+			if len(vals) == 0 {
+				continue
+			}
+			if b.FieldFilter != nil {
+				f, err := b.mapEntryField(sf, ev, tags, path)
+				if err != nil || !b.FieldFilter(b.context(), f) {
+					continue
+				}
+			}
+			str := vals[len(vals)-1]
+			ok = b.decodeInto(ev, name, str, errs)
+			// Clearing a pointer doesn't count as setting it, but an
+			// existing entry should still be cleared.
+			if !ok && str == "" && ev.Kind() == reflect.Ptr && target.MapIndex(kv).IsValid() {
+				target.SetMapIndex(kv, ev)
+			}
+		}
+		if ok {
+			target.SetMapIndex(kv, ev)
+			set = true
+		}
+	}
+	if set && rf.Kind() == reflect.Ptr {
+		rf.Set(target.Addr())
+	}
+	return set
+}
+
+// allowed returns true if the Builder's FieldFilter, if any, allows the
+// field for rv. The field is built the same way it is when rendering, so
+// the filter sees the same name, label, and so on.
+func (b *Builder) allowed(sf reflect.StructField, rv reflect.Value, tags map[string]string, path []string) bool {
+	if b.FieldFilter == nil {
+		return true
+	}
+	f, err := b.field(sf, rv, tags, path)
+	return err == nil && b.FieldFilter(b.context(), f)
+}
+
+// hasPrefix returns true if any of the values are for a field nested
+// inside of prefix, eg Parent.Name or Parent[0] for a prefix of Parent.
+func hasPrefix(values url.Values, prefix string) bool {
+	for key := range values {
+		if strings.HasPrefix(key, prefix+".") || strings.HasPrefix(key, prefix+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// indexes returns the sorted, distinct indexes of elements submitted for
+// the slice named prefix.
+func (b *Builder) indexes(values url.Values, prefix string) []int {
+	seen := make(map[int]bool)
+	var ret []int
+	for key := range values {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		rest := key[len(prefix):]
+		var digits string
+		if b.bracketIndexes() {
+			end := strings.Index(rest, "]")
+			if !strings.HasPrefix(rest, "[") || end < 0 {
+				continue
+			}
+			digits = rest[1:end]
+		} else {
+			if !strings.HasPrefix(rest, ".") {
+				continue
+			}
+			rest = rest[1:]
+			if end := strings.IndexAny(rest, ".["); end >= 0 {
+				rest = rest[:end]
+			}
+			digits = rest
+		}
+		i, err := strconv.Atoi(digits)
+		if err != nil || i < 0 || seen[i] {
+			continue
+		}
+		seen[i] = true
+		ret = append(ret, i)
+	}
+	sort.Ints(ret)
+	return ret
+}
+
+// decodeInto decodes str into rv, allocating a new value if rv is a
+// pointer. Pointers are set to nil when str is empty, so an optional
+// *int that was left blank isn't set to 0. It returns true if rv was set,
+// which doesn't include a pointer being cleared, so that a nested struct
+// isn't allocated just to hold nil pointers.
+func (b *Builder) decodeInto(rv reflect.Value, name, str string, errs *Errors) bool {
+	if rv.Kind() != reflect.Ptr {
+		return b.decodeValue(rv, name, str, errs)
+	}
+	if str == "" {
+		rv.Set(reflect.Zero(rv.Type()))
+		return false
+	}
+	nv := reflect.New(rv.Type().Elem())
+	if !b.decodeValue(nv.Elem(), name, str, errs) {
+		return false
+	}
+	rv.Set(nv)
+	return true
+}
+
+// decodeValue converts str into the type of rv using the type's Codec and
+// sets it. Empty strings are decoded as the zero value for any non-string
+// types. Types without a Codec are skipped, and conversion failures are
+// added to errs. It returns true if rv was set.
+func (b *Builder) decodeValue(rv reflect.Value, name, str string, errs *Errors) bool {
+	// Values that can hold anything, like those in a map[string]interface{},
+	// are left as strings.
+	if rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
+		rv.Set(reflect.ValueOf(str))
+		return true
+	}
+	if str == "" && rv.Kind() != reflect.String {
+		rv.Set(reflect.Zero(rv.Type()))
+		return true
+	}
+	c := b.codec(rv.Type(), "")
+	if c == nil {
+		return false
+	}
+	v, err := c.Parse(str)
+	if err == nil && !v.Type().AssignableTo(rv.Type()) {
+		if !v.Type().ConvertibleTo(rv.Type()) {
+			err = fmt.Errorf("form: codec returned a %v, want %v", v.Type(), rv.Type())
+		} else {
+			v = v.Convert(rv.Type())
+		}
+	}
+	if err != nil {
+		errs.add(&DecodeError{Field: name, Value: str, Kind: rv.Kind(), Err: err})
+		return false
+	}
+	rv.Set(v)
+	return true
+}
+
+// DecodeError is returned (inside of an Errors value) by Decode when a
+// submitted value can't be converted into the type of its field. It
+// implements the FieldErrorer interface so it can be passed back into
+// Inputs to render the error alongside the field.
+type DecodeError struct {
+	// Field is the name of the input, as rendered by the Builder.
+	Field string
+	// Value is the submitted value that couldn't be decoded.
+	Value string
+	// Kind is the reflect.Kind we were attempting to decode into.
+	Kind reflect.Kind
+	// Err is the underlying error returned by the field type's Codec.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("form: decoding %q into field %v: %v", e.Value, e.Field, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// FieldError returns a user friendly error message based on the kind of
+// value we were attempting to decode.
+func (e *DecodeError) FieldError() (field, err string) {
+	switch e.Kind {
+	case reflect.Bool:
+		return e.Field, "must be true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return e.Field, "must be a whole number"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return e.Field, "must be a non-negative whole number"
+	case reflect.Float32, reflect.Float64:
+		return e.Field, "must be a number"
+	}
+	return e.Field, "is invalid"
+}
+
+// ErrorCode returns a code based on the kind of value we were attempting
+// to decode, which is one of not_bool, not_int, not_uint, not_number, or
+// invalid. The submitted value is provided as the value param.
+func (e *DecodeError) ErrorCode() (code string, params map[string]interface{}) {
+	params = map[string]interface{}{"value": e.Value}
+	switch e.Kind {
+	case reflect.Bool:
+		return "not_bool", params
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "not_int", params
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "not_uint", params
+	case reflect.Float32, reflect.Float64:
+		return "not_number", params
+	}
+	return "invalid", params
+}
@@ -0,0 +1,524 @@
+package form
+
+import (
+	"database/sql"
+	"errors"
+	"html/template"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuilder_Decode(t *testing.T) {
+	type address struct {
+		Street1 string
+		Zip     int
+	}
+	type customer struct {
+		Name     string `form:"name=full-name"`
+		Age      int
+		Rating   float64
+		Admin    bool
+		Secret   string `form:"-"`
+		Nickname *string
+		Notes    []byte
+		Address  *address
+		Billing  address
+	}
+	nickname := "Mike"
+
+	tests := []struct {
+		name   string
+		values url.Values
+		want   customer
+	}{
+		{
+			name:   "empty",
+			values: url.Values{},
+			want:   customer{},
+		}, {
+			name: "simple values",
+			values: url.Values{
+				"full-name": {"Michael Scott"},
+				"Age":       {"42"},
+				"Rating":    {"4.5"},
+				"Admin":     {"on"},
+			},
+			want: customer{
+				Name:   "Michael Scott",
+				Age:    42,
+				Rating: 4.5,
+				Admin:  true,
+			},
+		}, {
+			name: "ignored and go names",
+			values: url.Values{
+				"Name":   {"Michael Scott"},
+				"Secret": {"secret info"},
+			},
+			want: customer{},
+		}, {
+			name: "nested",
+			values: url.Values{
+				"Address.Street1": {"123 Test St"},
+				"Billing.Zip":     {"12345"},
+			},
+			want: customer{
+				Address: &address{Street1: "123 Test St"},
+				Billing: address{Zip: 12345},
+			},
+		}, {
+			name: "pointer",
+			values: url.Values{
+				"Nickname": {"Mike"},
+			},
+			want: customer{
+				Nickname: &nickname,
+			},
+		}, {
+			name: "bytes",
+			values: url.Values{
+				"Notes": {"hi"},
+			},
+			want: customer{
+				Notes: []byte("hi"),
+			},
+		}, {
+			name: "last value wins",
+			values: url.Values{
+				"Admin": {"false", "true"},
+			},
+			want: customer{
+				Admin: true,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b Builder
+			var got customer
+			err := b.Decode(&got, tc.values)
+			if err != nil {
+				t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Builder.Decode() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_Decode_nestedTags(t *testing.T) {
+	type address struct {
+		Street1 string
+	}
+	type order struct {
+		Billing  address  `form:"name=billing"`
+		Shipping *address `form:"name=shipping"`
+		Internal address  `form:"-"`
+	}
+	var b Builder
+	var got order
+	err := b.Decode(&got, url.Values{
+		"billing.Street1":  {"123 Test St"},
+		"shipping.Street1": {"1725 Slough Ave"},
+		"Billing.Street1":  {"ignored"},
+		"Internal.Street1": {"ignored"},
+	})
+	if err != nil {
+		t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+	}
+	want := order{
+		Billing:  address{"123 Test St"},
+		Shipping: &address{"1725 Slough Ave"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Builder.Decode() = %+v, want %+v", got, want)
+	}
+}
+
+func TestBuilder_Decode_cycles(t *testing.T) {
+	type category struct {
+		Name   string
+		Parent *category
+	}
+	var b Builder
+	var got category
+	if err := b.Decode(&got, url.Values{"Name": {"Shoes"}}); err != nil {
+		t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+	}
+	if want := (category{Name: "Shoes"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Builder.Decode() = %+v, want %+v", got, want)
+	}
+
+	got = category{}
+	err := b.Decode(&got, url.Values{"Name": {"Shoes"}, "Parent.Parent.Name": {"Clothing"}})
+	if err != nil {
+		t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+	}
+	want := category{Name: "Shoes", Parent: &category{Parent: &category{Name: "Clothing"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Builder.Decode() = %+v, want %+v", got, want)
+	}
+
+	b.MaxDepth = 2
+	err = b.Decode(&got, url.Values{"Parent.Parent.Name": {"Clothing"}})
+	var de *DepthError
+	if !errors.As(err, &de) {
+		t.Errorf("Builder.Decode() err = %v, want a *DepthError", err)
+	}
+}
+
+func TestBuilder_Decode_pointers(t *testing.T) {
+	type patch struct {
+		Name  *string
+		Age   *int
+		Count *int
+		Score *float64
+	}
+	name, age := "Michael", 42
+	got := patch{Name: &name, Age: &age}
+	var b Builder
+	err := b.Decode(&got, url.Values{
+		"Name":  {""},
+		"Count": {"0"},
+		"Score": {""},
+	})
+	if err != nil {
+		t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+	}
+	zero := 0
+	want := patch{Age: &age, Count: &zero}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Builder.Decode() = %+v, want %+v", got, want)
+	}
+
+	// Blank values don't cause nested structs to be allocated, but they
+	// still clear existing pointers.
+	type address struct {
+		Street *string
+	}
+	type customer struct {
+		Address *address
+		Extras  map[string]*int
+	}
+	gotc := customer{Extras: map[string]*int{"fee": &age}}
+	err = b.Decode(&gotc, url.Values{
+		"Address.Street": {""},
+		"Extras.fee":     {""},
+		"Extras.tip":     {""},
+	})
+	if err != nil {
+		t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+	}
+	wantc := customer{Extras: map[string]*int{"fee": nil}}
+	if !reflect.DeepEqual(gotc, wantc) {
+		t.Errorf("Builder.Decode() = %+v, want %+v", gotc, wantc)
+	}
+}
+
+func TestBuilder_Decode_errors(t *testing.T) {
+	var b Builder
+	var dst struct {
+		Name    string
+		Age     int
+		Address struct {
+			Zip uint
+		}
+	}
+	err := b.Decode(&dst, url.Values{
+		"Name":        {"Michael Scott"},
+		"Age":         {"forty"},
+		"Address.Zip": {"-1"},
+	})
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("Builder.Decode() err = %v, want a *DecodeError", err)
+	}
+	if dst.Name != "Michael Scott" {
+		t.Errorf("Builder.Decode() Name = %q, want %q", dst.Name, "Michael Scott")
+	}
+
+	tpl := template.Must(template.New("").Funcs(FuncMap()).Parse(
+		`{{.Name}}:{{range errors}}{{.}}{{end}};`,
+	))
+	b.InputTemplate = tpl
+	got, rerr := b.Inputs(dst, err.(Errors)...)
+	if rerr != nil {
+		t.Fatalf("Builder.Inputs() err = %v, want %v", rerr, nil)
+	}
+	want := template.HTML(strings.Join([]string{
+		"Name:;",
+		"Age:must be a whole number;",
+		"Address.Zip:must be a non-negative whole number;",
+	}, ""))
+	if got != want {
+		t.Errorf("Builder.Inputs() = %v, want %v", got, want)
+	}
+}
+
+func TestBuilder_Decode_invalid(t *testing.T) {
+	var b Builder
+	var s struct{ Name string }
+	var str string
+	tests := []struct {
+		name string
+		dst  interface{}
+	}{
+		{"non-pointer", s},
+		{"nil pointer", (*struct{ Name string })(nil)},
+		{"non-struct", &str},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := b.Decode(tc.dst, url.Values{}); err == nil {
+				t.Errorf("Builder.Decode() err = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestBuilder_Decode_checkboxes(t *testing.T) {
+	type prefs struct {
+		Active bool
+	}
+	type settings struct {
+		Admin    bool
+		Terms    bool
+		Subtitle bool `form:"type=text"`
+		Notify   bool `form:"name=notify"`
+		Beta     *bool
+		Opt      sql.NullBool
+		Prefs    *prefs
+	}
+	yes := true
+	checked := func() settings {
+		return settings{
+			Admin: true, Terms: true, Subtitle: true, Notify: true,
+			Beta: &yes, Opt: sql.NullBool{Bool: true, Valid: true},
+		}
+	}
+	no := false
+	tests := []struct {
+		name    string
+		uncheck bool
+		arg     settings
+		values  url.Values
+		want    settings
+	}{
+		{
+			name:   "missing fields are untouched",
+			arg:    checked(),
+			values: url.Values{"Admin": {"on"}},
+			want:   checked(),
+		}, {
+			// Admin is checked, but the others were unchecked so they
+			// aren't submitted at all.
+			name:    "unchecked",
+			uncheck: true,
+			arg:     checked(),
+			values:  url.Values{"Admin": {"on"}},
+			want: settings{
+				Admin: true, Subtitle: true,
+				Beta: &no, Opt: sql.NullBool{Valid: true},
+			},
+		}, {
+			name:    "nested pointers aren't allocated",
+			uncheck: true,
+			values:  url.Values{},
+			want:    settings{Beta: &no, Opt: sql.NullBool{Valid: true}},
+		}, {
+			name:    "nested structs",
+			uncheck: true,
+			arg:     settings{Prefs: &prefs{Active: true}},
+			values:  url.Values{},
+			want:    settings{Beta: &no, Opt: sql.NullBool{Valid: true}, Prefs: &prefs{}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &Builder{UncheckMissing: tc.uncheck}
+			got := tc.arg
+			if err := b.Decode(&got, tc.values); err != nil {
+				t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Builder.Decode() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_Decode_unsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		dst  interface{}
+		path string
+	}{
+		{"int keyed map", &map[int]string{}, ""},
+		{"int keyed map field", &struct{ Lookup map[int]string }{}, "Lookup"},
+		{"int keyed map elements", &struct{ Lookups []map[int]string }{}, "Lookups"},
+		{"int keyed map values", &struct{ Lookups map[string]map[int]string }{}, "Lookups"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b Builder
+			err := b.Decode(tc.dst, url.Values{"Lookup": {"1"}})
+			var ute *UnsupportedTypeError
+			if !errors.As(err, &ute) {
+				t.Fatalf("Builder.Decode() err = %v, want an *UnsupportedTypeError", err)
+			}
+			if ute.Path != tc.path {
+				t.Errorf("Builder.Decode() err Path = %q, want %q", ute.Path, tc.path)
+			}
+		})
+	}
+}
+
+func TestBuilder_Decode_repeated(t *testing.T) {
+	type item struct {
+		Name string
+		Qty  int
+	}
+	type order struct {
+		Tags   []string `form:"name=tag"`
+		IDs    []int
+		Phones [3]string
+		Items  []item
+		Extras []*item
+		Notes  *[]string
+	}
+	notes := []string{"fragile"}
+	tests := []struct {
+		name     string
+		brackets bool
+		values   url.Values
+		want     order
+	}{
+		{
+			name:   "empty",
+			values: url.Values{},
+			want:   order{},
+		}, {
+			name: "dots",
+			values: url.Values{
+				"tag.0":         {"a"},
+				"tag.2":         {"c"},
+				"tag.1":         {"b"},
+				"IDs.0":         {"1"},
+				"IDs.10":        {"11"},
+				"Phones.2":      {"555-1234"},
+				"Items.0.Name":  {"Widget"},
+				"Items.0.Qty":   {"2"},
+				"Items.3.Name":  {"Gadget"},
+				"Extras.0.Name": {"Gizmo"},
+				"Notes.0":       {"fragile"},
+			},
+			want: order{
+				Tags:   []string{"a", "b", "c"},
+				IDs:    []int{1, 11},
+				Phones: [3]string{"", "", "555-1234"},
+				Items:  []item{{"Widget", 2}, {"Gadget", 0}},
+				Extras: []*item{{Name: "Gizmo"}},
+				Notes:  &notes,
+			},
+		}, {
+			name:     "brackets",
+			brackets: true,
+			values: url.Values{
+				"tag[0]":        {"a"},
+				"tag.1":         {"ignored"},
+				"Items[0].Name": {"Widget"},
+				"Items[1].Qty":  {"3"},
+			},
+			want: order{
+				Tags:  []string{"a"},
+				Items: []item{{"Widget", 0}, {"", 3}},
+			},
+		}, {
+			name: "multiple values",
+			values: url.Values{
+				"tag": {"a", "b"},
+				"IDs": {"1", "2"},
+			},
+			want: order{
+				Tags: []string{"a", "b"},
+				IDs:  []int{1, 2},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &Builder{BracketIndexes: tc.brackets}
+			var got order
+			if err := b.Decode(&got, tc.values); err != nil {
+				t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Builder.Decode() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_Decode_maps(t *testing.T) {
+	type address struct {
+		Street1 string
+	}
+	type settings struct {
+		Name      string
+		Prefs     map[string]string
+		Limits    map[string]int `form:"name=limit"`
+		Addresses map[string]*address
+	}
+
+	var b Builder
+	got := settings{
+		Prefs: map[string]string{"theme": "light", "lang": "en"},
+	}
+	err := b.Decode(&got, url.Values{
+		"Name":                     {"Michael"},
+		"Prefs.theme":              {"dark"},
+		"Prefs.font.size":          {"12"},
+		"limit.users":              {"10"},
+		"Addresses.home.Street1":   {"123 Test St"},
+		"Addresses.office.Street1": {"1725 Slough Ave"},
+	})
+	if err != nil {
+		t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+	}
+	want := settings{
+		Name:   "Michael",
+		Prefs:  map[string]string{"theme": "dark", "lang": "en", "font.size": "12"},
+		Limits: map[string]int{"users": 10},
+		Addresses: map[string]*address{
+			"home":   {"123 Test St"},
+			"office": {"1725 Slough Ave"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Builder.Decode() = %+v, want %+v", got, want)
+	}
+
+	var top map[string]interface{}
+	err = b.Decode(&top, url.Values{"theme": {"dark"}, "count": {"3"}})
+	if err != nil {
+		t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+	}
+	wantTop := map[string]interface{}{"theme": "dark", "count": "3"}
+	if !reflect.DeepEqual(top, wantTop) {
+		t.Errorf("Builder.Decode() = %+v, want %+v", top, wantTop)
+	}
+
+	// Keys that are present without any values are skipped.
+	empty := settings{Prefs: map[string]string{"theme": "light"}}
+	err = b.Decode(&empty, url.Values{"Prefs.theme": {}, "Prefs.lang": {"en"}, "limit.users": {}})
+	if err != nil {
+		t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+	}
+	wantEmpty := settings{Prefs: map[string]string{"theme": "light", "lang": "en"}}
+	if !reflect.DeepEqual(empty, wantEmpty) {
+		t.Errorf("Builder.Decode() = %+v, want %+v", empty, wantEmpty)
+	}
+}
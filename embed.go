@@ -0,0 +1,181 @@
+package form
+
+import (
+	"reflect"
+	"sort"
+)
+
+// structField is a field of a struct as seen by the Builder. Its Index is
+// the full path of indexes used to reach the field, which is longer than
+// one for fields promoted from embedded structs.
+type structField struct {
+	reflect.StructField
+	tags map[string]string
+}
+
+// structFields returns the fields of the struct type t that are rendered
+// and decoded by the Builder, in order. Unexported fields and fields with
+// the `form:"-"` tag are skipped, and the fields of embedded structs are
+// promoted into t using the same rules as encoding/json:
+//
+//   - Embedded structs (and pointers to structs) have their fields
+//     promoted unless they have a name tag or the prefix flag, eg
+//     `form:"prefix"`, in which case they are treated like any other
+//     nested struct and their name is used as a prefix.
+//   - When multiple fields have the same name, the least nested one is
+//     used. If there are several at that depth, the one with a name tag is
+//     used, and if that doesn't settle it they are all ignored.
+//
+// Embedded types that are unexported are skipped unless they are structs,
+// just like encoding/json, as only the fields of a struct can be read.
+func (b *Builder) structFields(t reflect.Type) []structField {
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+
+	var candidates []candidate
+	visited := make(map[reflect.Type]bool)
+	current := []embedded{{t: t}}
+	for depth := 0; len(current) > 0; depth++ {
+		var next []embedded
+		for _, e := range current {
+			// An embedded struct that has already been walked, eg via
+			// embedding a pointer to itself, can't add anything new.
+			if visited[e.t] {
+				continue
+			}
+			visited[e.t] = true
+			for i := 0; i < e.t.NumField(); i++ {
+				sf := e.t.Field(i)
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if sf.PkgPath != "" && (!sf.Anonymous || ft.Kind() != reflect.Struct) {
+					continue
+				}
+				tags := parseTags(sf.Tag.Get("form"))
+				if _, ok := tags["-"]; ok {
+					continue
+				}
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+
+				name, tagged := tags["name"]
+				if !tagged {
+					_, tagged = b.tagName(sf)
+				}
+				_, prefixed := tags["prefix"]
+				if sf.Anonymous && ft.Kind() == reflect.Struct && !b.isLeaf(ft) && !tagged && !prefixed {
+					next = append(next, embedded{t: ft, index: index})
+					continue
+				}
+				// The fields of an unexported struct can still be read, but
+				// a value of an unexported type can't be.
+				if sf.PkgPath != "" && b.isLeaf(ft) {
+					continue
+				}
+				if _, ok := tags["name"]; !ok {
+					name = b.fieldName(sf)
+				}
+				sf.Index = index
+				candidates = append(candidates, candidate{
+					structField: structField{StructField: sf, tags: tags},
+					name:        name,
+					tagged:      tagged,
+					depth:       depth,
+				})
+			}
+		}
+		current = next
+	}
+
+	// Figure out which field wins for each name.
+	byName := make(map[string][]candidate)
+	for _, c := range candidates {
+		byName[c.name] = append(byName[c.name], c)
+	}
+	var ret []structField
+	for _, cs := range byName {
+		if c, ok := dominant(cs); ok {
+			ret = append(ret, c.structField)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return lessIndex(ret[i].Index, ret[j].Index)
+	})
+	return ret
+}
+
+// candidate is a field that structFields might return, along with the
+// information needed to resolve conflicts between fields with the same
+// name.
+type candidate struct {
+	structField
+	name   string
+	tagged bool
+	depth  int
+}
+
+// dominant returns the field that wins out of several with the same name,
+// as described by structFields. ok is false if there isn't a winner.
+func dominant(cs []candidate) (c candidate, ok bool) {
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].depth != cs[j].depth {
+			return cs[i].depth < cs[j].depth
+		}
+		return cs[i].tagged && !cs[j].tagged
+	})
+	if len(cs) > 1 && cs[0].depth == cs[1].depth && cs[0].tagged == cs[1].tagged {
+		return candidate{}, false
+	}
+	return cs[0], true
+}
+
+// lessIndex orders fields by their index paths, which is the order they
+// are declared in once embedded structs are expanded.
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// fieldByIndex returns the field of rv at index, which may pass through
+// embedded structs. ok is false if a nil embedded pointer is in the way.
+func fieldByIndex(rv reflect.Value, index []int) (f reflect.Value, ok bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+// setFieldByIndex sets the field of rv at index to v, allocating any nil
+// embedded pointers that are in the way. It returns false if a pointer
+// couldn't be allocated because its type is unexported.
+func setFieldByIndex(rv reflect.Value, index []int, v reflect.Value) bool {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return false
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	rv.Set(v)
+	return true
+}
@@ -0,0 +1,174 @@
+package form
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testBase struct {
+	ID      int
+	Created time.Time
+}
+
+type testAudit struct {
+	ID     string
+	Author string
+}
+
+// Base is exported as nil pointers to unexported embedded structs can't
+// be allocated when decoding.
+type Base struct {
+	ID int
+}
+
+type testAddress struct {
+	Street1 string
+}
+
+type testNote string
+
+type testLower struct {
+	Hidden string
+}
+
+func Test_fields_embedded(t *testing.T) {
+	type meta struct {
+		Name  string
+		Value interface{}
+	}
+	tests := []struct {
+		name string
+		arg  interface{}
+		want []meta
+	}{
+		{
+			name: "unexported fields are skipped",
+			arg: struct {
+				Name   string
+				secret string
+			}{"Michael", "shh"},
+			want: []meta{{"Name", "Michael"}},
+		}, {
+			name: "promoted",
+			arg: struct {
+				testBase
+				Name string
+			}{testBase{ID: 1}, "Michael"},
+			want: []meta{{"ID", 1}, {"Created", nil}, {"Name", "Michael"}},
+		}, {
+			name: "nil pointer promoted",
+			arg: struct {
+				*testBase
+				Name string
+			}{Name: "Michael"},
+			want: []meta{{"ID", 0}, {"Created", nil}, {"Name", "Michael"}},
+		}, {
+			name: "unexported type promoted",
+			arg: struct {
+				testLower
+			}{testLower{"visible"}},
+			want: []meta{{"Hidden", "visible"}},
+		}, {
+			name: "prefix",
+			arg: struct {
+				testAddress `form:"prefix"`
+				Home        testAddress `form:"name=home"`
+				*testBase   `form:"name=base"`
+			}{testAddress: testAddress{"123 Test St"}},
+			want: []meta{
+				{"testAddress.Street1", "123 Test St"},
+				{"home.Street1", ""},
+				{"base.ID", 0},
+				{"base.Created", nil},
+			},
+		}, {
+			name: "shallower field wins",
+			arg: struct {
+				testBase
+				ID string
+			}{testBase{ID: 1}, "abc"},
+			want: []meta{{"Created", nil}, {"ID", "abc"}},
+		}, {
+			name: "conflicts at the same depth are ignored",
+			arg: struct {
+				testBase
+				testAudit
+			}{testBase{ID: 1}, testAudit{ID: "a", Author: "Michael"}},
+			want: []meta{{"Created", nil}, {"Author", "Michael"}},
+		}, {
+			name: "tagged field wins a conflict",
+			arg: struct {
+				testBase
+				testAudit `form:"-"`
+				Audit     struct {
+					ID string `form:"name=ID"`
+				}
+			}{testBase: testBase{ID: 1}},
+			want: []meta{{"ID", 1}, {"Created", nil}, {"ID", ""}},
+		}, {
+			name: "embedded non-struct",
+			arg: struct {
+				testNote
+				time.Duration
+			}{"hello", time.Second},
+			want: []meta{{"Duration", time.Second}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b Builder
+			fields, err := b.fields(tc.arg)
+			if err != nil {
+				t.Fatalf("fields() err = %v, want %v", err, nil)
+			}
+			var got []meta
+			for _, f := range fields {
+				got = append(got, meta{f.Name, f.Value})
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("fields() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_Decode_embedded(t *testing.T) {
+	type form struct {
+		*Base
+		testLower
+		Home   testAddress `form:"prefix"`
+		Name   string
+		secret string
+	}
+	var b Builder
+	var got form
+	err := b.Decode(&got, url.Values{
+		"ID":           {"7"},
+		"Hidden":       {"visible"},
+		"Home.Street1": {"123 Test St"},
+		"Name":         {"Michael"},
+		"secret":       {"ignored"},
+	})
+	if err != nil {
+		t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+	}
+	want := form{
+		Base:      &Base{ID: 7},
+		testLower: testLower{"visible"},
+		Home:      testAddress{"123 Test St"},
+		Name:      "Michael",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Builder.Decode() = %+v, want %+v", got, want)
+	}
+
+	got = form{}
+	if err := b.Decode(&got, url.Values{"Name": {"Michael"}}); err != nil {
+		t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+	}
+	if got.Base != nil {
+		t.Errorf("Builder.Decode() Base = %+v, want nil", got.Base)
+	}
+}
@@ -0,0 +1,247 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/schema"
+)
+
+// FieldErrorer is an interface defining an error that represents something
+// wrong with a particular struct field. The name should correspond to the
+// name value used when building the HTML form, which by default is a period
+// separated list of all fields that lead up to the particular field (see
+// the Builder's Naming for other options).
+// Eg, in the following struct the Mouse field would have a key of Cat.Mouse:
+//
+//   type Dog struct {
+//     Cat: struct{
+//       Mouse string
+//     }
+//   }
+//
+// The top level Dog struct name is not used because this is unnecessary,
+// but any other nested struct names are necessary to properly determine
+// the field.
+//
+// It should also be noted that if you provide a custom field name, that
+// name should also be used in FieldErrorer implementations.
+//
+// FieldError, ValidationError, and DecodeError all implement this
+// interface, but you can also implement it with your own error types.
+type FieldErrorer interface {
+	FieldError() (field, err string)
+}
+
+// CodedError is an optional interface for field errors that also have a
+// code, eg "minlen", and the params used to build their message, eg
+// {"minlen": 3}. These make it possible to show a different message for
+// an error than the one it was created with, such as a localized one, via
+// the Builder's Messages. They are also provided to templates by the
+// error_entries function.
+type CodedError interface {
+	FieldErrorer
+	ErrorCode() (code string, params map[string]interface{})
+}
+
+// ErrorEntry is a single field error as provided to templates by the
+// error_entries function. Code and Params are only set for errors that
+// implement CodedError. Eg:
+//
+//   {{range error_entries}}
+//     <p class="error" data-code="{{.Code}}">{{.Message}}</p>
+//   {{end}}
+type ErrorEntry struct {
+	Message string
+	Code    string
+	Params  map[string]interface{}
+}
+
+// interpolate replaces each {name} in msg with the param of that name.
+func interpolate(msg string, params map[string]interface{}) string {
+	if len(params) == 0 {
+		return msg
+	}
+	oldnew := make([]string, 0, 2*len(params))
+	for k, v := range params {
+		oldnew = append(oldnew, "{"+k+"}", fmt.Sprint(v))
+	}
+	return strings.NewReplacer(oldnew...).Replace(msg)
+}
+
+// FieldError is a simple FieldErrorer for when a field's value is wrong,
+// eg because an email address is already taken:
+//
+//   return &form.FieldError{Field: "Email", Message: "is already taken"}
+//
+// See Errors.Add for an easy way to collect several of these.
+type FieldError struct {
+	// Field is the name of the input, as rendered by the Builder.
+	Field string
+	// Message is the user facing error message, eg "is already taken".
+	Message string
+	// Code and Params are optional, and are returned by ErrorCode. See
+	// the CodedError interface for more info.
+	Code   string
+	Params map[string]interface{}
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("form: %v %v", e.Field, e.Message)
+}
+
+// FieldError returns the field name and error message.
+func (e *FieldError) FieldError() (field, err string) {
+	return e.Field, e.Message
+}
+
+// ErrorCode returns the error's Code and Params.
+func (e *FieldError) ErrorCode() (code string, params map[string]interface{}) {
+	return e.Code, e.Params
+}
+
+// Errors is a collection of errors, typically field errors, that is
+// returned as a single error by methods like Decode. It is a []error
+// under the hood so it can be passed directly into the
+// inputs_and_errors_for template function, and errors.Is and errors.As
+// will inspect each error in it.
+//
+// Handlers can use it to gather errors from several places before
+// rendering the form again, eg:
+//
+//   var errs form.Errors
+//   if err := fb.Decode(&signup, r.PostForm); err != nil {
+//     errors.As(err, &errs)
+//   }
+//   if emailTaken(signup.Email) {
+//     errs.Add("Email", "is already taken")
+//   }
+//   if errs.Len() > 0 {
+//     // render the form with errs
+//   }
+type Errors []error
+
+func (errs Errors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors in the collection.
+func (errs Errors) Unwrap() []error {
+	return errs
+}
+
+// Is reports whether any error in the collection matches target. This is
+// used by errors.Is in versions of Go that don't support Unwrap() []error.
+func (errs Errors) Is(target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error in the collection that matches target. This is
+// used by errors.As in versions of Go that don't support Unwrap() []error.
+func (errs Errors) As(target interface{}) bool {
+	for _, err := range errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add adds a *FieldError for field with the provided message.
+func (errs *Errors) Add(field, msg string) {
+	errs.add(&FieldError{Field: field, Message: msg})
+}
+
+// For returns the messages of every error in the collection for field,
+// including those inside of nested collections. See flattenErrors.
+func (errs Errors) For(field string) []string {
+	var ret []string
+	for _, err := range flattenErrors(errs) {
+		var fe FieldErrorer
+		if !errors.As(err, &fe) {
+			continue
+		}
+		if f, msg := fe.FieldError(); f == field {
+			ret = append(ret, msg)
+		}
+	}
+	return ret
+}
+
+// Has returns true if the collection has any errors for field.
+func (errs Errors) Has(field string) bool {
+	return len(errs.For(field)) > 0
+}
+
+// Len returns the number of errors in the collection.
+func (errs Errors) Len() int {
+	return len(errs)
+}
+
+func (errs *Errors) add(err error) {
+	*errs = append(*errs, err)
+}
+
+// flattenErrors returns every error contained in errs, recursively
+// expanding errors that hold several others so that each of them can be
+// matched with its field. These are:
+//
+//   - Errors that implement Unwrap() []error, like Errors and the errors
+//     returned by errors.Join.
+//   - schema.MultiError, which is returned by gorilla/schema's Decoder.
+//     Its errors are ordered by key.
+//
+// A collection can also be wrapped, eg via fmt.Errorf("...: %w", errs).
+// An error that wraps a field error is returned as-is, so the field error
+// can still be found with errors.As. The errors gorilla/schema returns for
+// a specific key are replaced with field errors, as described in
+// FromSchema.
+func flattenErrors(errs []error) []error {
+	var ret []error
+	for _, err := range errs {
+		ret = appendErrors(ret, err)
+	}
+	return ret
+}
+
+func appendErrors(dst []error, err error) []error {
+	if err == nil {
+		return dst
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if fe, ok := schemaFieldError(e); ok {
+			return append(dst, fe)
+		}
+		switch e := e.(type) {
+		case FieldErrorer:
+			return append(dst, err)
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				dst = appendErrors(dst, err)
+			}
+			return dst
+		case schema.MultiError:
+			keys := make([]string, 0, len(e))
+			for k := range e {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				dst = appendErrors(dst, e[k])
+			}
+			return dst
+		}
+	}
+	return append(dst, err)
+}
@@ -0,0 +1,322 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/schema"
+)
+
+func TestErrors(t *testing.T) {
+	var errs Errors
+	errs.Add("Email", "is required")
+	errs.Add("Email", "is taken")
+	errs = append(errs, fmt.Errorf("wrapped: %w", &ValidationError{Field: "Age", Rule: "min", Message: "must be at least 13"}))
+	errs = append(errs, errors.New("not a field error"))
+
+	if got := errs.Len(); got != 4 {
+		t.Errorf("Errors.Len() = %d, want %d", got, 4)
+	}
+	tests := []struct {
+		field string
+		want  []string
+	}{
+		{"Email", []string{"is required", "is taken"}},
+		{"Age", []string{"must be at least 13"}},
+		{"Name", nil},
+	}
+	for _, tc := range tests {
+		if got := errs.For(tc.field); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Errors.For(%q) = %v, want %v", tc.field, got, tc.want)
+		}
+		if got, want := errs.Has(tc.field), tc.want != nil; got != want {
+			t.Errorf("Errors.Has(%q) = %v, want %v", tc.field, got, want)
+		}
+	}
+	want := "form: Email is required; form: Email is taken; wrapped: form: Age must be at least 13; not a field error"
+	if got := errs.Error(); got != want {
+		t.Errorf("Errors.Error() = %q, want %q", got, want)
+	}
+}
+
+func TestErrors_IsAs(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	var err error = fmt.Errorf("decoding: %w", Errors{
+		&FieldError{Field: "Email", Message: "is taken"},
+		fmt.Errorf("wrapped: %w", sentinel),
+	})
+	if !errors.Is(err, sentinel) {
+		t.Errorf("errors.Is(err, sentinel) = false, want true")
+	}
+	if errors.Is(err, errors.New("other")) {
+		t.Errorf("errors.Is(err, other) = true, want false")
+	}
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Field != "Email" {
+		t.Errorf("errors.As(err, *FieldError) = %v, want the Email error", fe)
+	}
+	var errs Errors
+	if !errors.As(err, &errs) || errs.Len() != 2 {
+		t.Errorf("errors.As(err, Errors) = %v, want both errors", errs)
+	}
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		t.Errorf("errors.As(err, *ValidationError) = true, want false")
+	}
+	// The methods are also called directly by older versions of Go.
+	if !errs.Is(sentinel) || !errs.As(&fe) {
+		t.Errorf("Errors.Is() or Errors.As() = false, want true")
+	}
+}
+
+func TestBuilder_Inputs_Errors(t *testing.T) {
+	tpl := template.Must(template.New("").Funcs(FuncMap()).Parse(`<label>{{.Label}}</label>{{range errors}}<p>{{.}}</p>{{end}}`))
+	b := &Builder{InputTemplate: tpl}
+	var errs Errors
+	errs.Add("Email", "is taken")
+	errs.Add("Name", "is required")
+	got, err := b.Inputs(struct{ Name, Email string }{}, errs...)
+	if err != nil {
+		t.Fatalf("Builder.Inputs() err = %v, want %v", err, nil)
+	}
+	want := template.HTML(`<label>Name</label><p>is required</p><label>Email</label><p>is taken</p>`)
+	if got != want {
+		t.Errorf("Builder.Inputs() = %v, want %v", got, want)
+	}
+}
+
+func TestBuilder_Inputs_formErrors(t *testing.T) {
+	tpl := template.Must(template.New("").Funcs(FuncMap()).Parse(`<label>{{.Label}}</label>{{range errors}}<p>{{.}}</p>{{end}}`))
+	etpl := template.Must(template.New("").Parse(`<ul>{{range .}}<li>{{.}}</li>{{end}}</ul>`))
+	arg := struct{ Name string }{}
+	saveErr := errors.New("could not save")
+	errs := []error{
+		&FieldError{Field: "Name", Message: "is required"},
+		saveErr,
+		errors.New("@errors.retry"),
+	}
+	tests := []struct {
+		name       string
+		tpl        *template.Template
+		errs       []error
+		want       template.HTML
+		wantLogged []error
+	}{
+		{
+			name:       "no errors template",
+			errs:       errs,
+			want:       `<label>Name</label><p>is required</p>`,
+			wantLogged: errs[1:],
+		}, {
+			name:       "errors template",
+			tpl:        etpl,
+			errs:       errs,
+			want:       `<ul><li>could not save</li><li>Please try again</li></ul><label>Name</label><p>is required</p>`,
+			wantLogged: errs[1:],
+		}, {
+			name: "only field errors",
+			tpl:  etpl,
+			errs: errs[:1],
+			want: `<label>Name</label><p>is required</p>`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var logged []error
+			b := &Builder{
+				InputTemplate:  tpl,
+				ErrorsTemplate: tc.tpl,
+				FormErrorFunc:  func(err error) { logged = append(logged, err) },
+				Translator:     MapTranslator{"": {"errors.retry": "Please try again"}},
+			}
+			got, err := b.Inputs(arg, tc.errs...)
+			if err != nil {
+				t.Fatalf("Builder.Inputs() err = %v, want %v", err, nil)
+			}
+			if got != tc.want {
+				t.Errorf("Builder.Inputs() = %v, want %v", got, tc.want)
+			}
+			if !reflect.DeepEqual(logged, tc.wantLogged) {
+				t.Errorf("FormErrorFunc() called with %v, want %v", logged, tc.wantLogged)
+			}
+		})
+	}
+}
+
+func TestBuilder_FuncMap_formErrors(t *testing.T) {
+	b := &Builder{InputTemplate: template.Must(template.New("").Parse(`<input name="{{.Name}}">`))}
+	page := template.Must(template.New("").Funcs(b.FuncMap()).Parse(
+		`{{range form_errors .Errors}}<p>{{.}}</p>{{end}}{{inputs_and_errors_for .Form .Errors}}`,
+	))
+	var sb strings.Builder
+	err := page.Execute(&sb, map[string]interface{}{
+		"Form":   struct{ Name string }{},
+		"Errors": []error{&FieldError{Field: "Name", Message: "is required"}, errors.New("could not save")},
+	})
+	if err != nil {
+		t.Fatalf("Execute() err = %v, want %v", err, nil)
+	}
+	if got, want := sb.String(), `<p>could not save</p><input name="Name">`; got != want {
+		t.Errorf("Execute() = %v, want %v", got, want)
+	}
+}
+
+// testJoinError is like the errors returned by errors.Join, which isn't
+// available in every version of Go that this package supports.
+type testJoinError []error
+
+func (e testJoinError) Error() string   { return "joined" }
+func (e testJoinError) Unwrap() []error { return e }
+
+func Test_flattenErrors(t *testing.T) {
+	name := &FieldError{Field: "Name", Message: "is required"}
+	email := &FieldError{Field: "Email", Message: "is taken"}
+	age := &ValidationError{Field: "Age", Rule: "min", Message: "must be at least 13"}
+	wrappedAge := fmt.Errorf("validating: %w", age)
+	other := errors.New("could not save")
+	tests := []struct {
+		name string
+		arg  []error
+		want []error
+	}{
+		{
+			name: "flat",
+			arg:  []error{name, other, nil},
+			want: []error{name, other},
+		}, {
+			name: "joined",
+			arg:  []error{testJoinError{name, testJoinError{email, other}}},
+			want: []error{name, email, other},
+		}, {
+			name: "wrapped collection",
+			arg:  []error{fmt.Errorf("decoding: %w", Errors{name, wrappedAge})},
+			want: []error{name, wrappedAge},
+		}, {
+			name: "schema multi error",
+			arg:  []error{schema.MultiError{"Name": name, "Email": email, "Other": other}},
+			want: []error{email, name, other},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := flattenErrors(tc.arg); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("flattenErrors() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_Inputs_joinedErrors(t *testing.T) {
+	tpl := template.Must(template.New("").Funcs(FuncMap()).Parse(`<label>{{.Label}}</label>{{range errors}}<p>{{.}}</p>{{end}}`))
+	etpl := template.Must(template.New("").Parse(`{{range .}}<b>{{.}}</b>{{end}}`))
+	b := &Builder{InputTemplate: tpl, ErrorsTemplate: etpl}
+	err := fmt.Errorf("signup: %w", testJoinError{
+		Errors{
+			&FieldError{Field: "Name", Message: "is required"},
+			&FieldError{Field: "Email", Message: "is invalid"},
+		},
+		schema.MultiError{"Email": &FieldError{Field: "Email", Message: "is taken"}},
+		errors.New("could not save"),
+	})
+	got, rerr := b.Inputs(struct{ Name, Email string }{}, err)
+	if rerr != nil {
+		t.Fatalf("Builder.Inputs() err = %v, want %v", rerr, nil)
+	}
+	want := template.HTML(`<b>could not save</b><label>Name</label><p>is required</p><label>Email</label><p>is invalid</p><p>is taken</p>`)
+	if got != want {
+		t.Errorf("Builder.Inputs() = %v, want %v", got, want)
+	}
+	if errs := (Errors{err}); !errs.Has("Email") || len(errs.For("Email")) != 2 {
+		t.Errorf("Errors.For(Email) = %v, want both Email errors", errs.For("Email"))
+	}
+}
+
+func Test_interpolate(t *testing.T) {
+	tests := []struct {
+		msg    string
+		params map[string]interface{}
+		want   string
+	}{
+		{"is required", nil, "is required"},
+		{"needs {minlen} or more", map[string]interface{}{"minlen": 3}, "needs 3 or more"},
+		{"{min}-{max}, not {value}", map[string]interface{}{"min": "1", "max": 5, "value": "x"}, "1-5, not x"},
+		{"{missing} stays", map[string]interface{}{"other": 1}, "{missing} stays"},
+	}
+	for _, tc := range tests {
+		if got := interpolate(tc.msg, tc.params); got != tc.want {
+			t.Errorf("interpolate(%q) = %q, want %q", tc.msg, got, tc.want)
+		}
+	}
+}
+
+func TestBuilder_Inputs_errorCodes(t *testing.T) {
+	tpl := template.Must(template.New("").Funcs(FuncMap()).Parse(
+		`{{.Name}}:{{range error_entries}}[{{.Code}}|{{.Message}}]{{end}}{{range errors}}({{.}}){{end}};`,
+	))
+	type signup struct {
+		Username string `form:"minlen=3"`
+		Age      int    `form:"min=13"`
+		Email    string
+		Nickname string
+	}
+	var errs Errors
+	if err := (&Builder{}).Validate(signup{Username: "mi", Age: 5}); !errors.As(err, &errs) {
+		t.Fatalf("Builder.Validate() err = %v, want Errors", err)
+	}
+	errs = append(errs,
+		&FieldError{Field: "Email", Message: "is taken", Code: "taken"},
+		&DecodeError{Field: "Nickname", Value: "x", Kind: reflect.String, Err: errors.New("bad")},
+	)
+	tests := []struct {
+		name string
+		b    *Builder
+		want string
+	}{
+		{
+			name: "no catalog",
+			b:    &Builder{},
+			want: "Username:[minlen|must be at least 3 characters](must be at least 3 characters);" +
+				"Age:[min|must be at least 13](must be at least 13);" +
+				"Email:[taken|is taken](is taken);" +
+				"Nickname:[invalid|is invalid](is invalid);",
+		}, {
+			name: "catalog",
+			b: &Builder{Messages: map[string]string{
+				"minlen":  "needs {minlen} or more characters",
+				"min":     "@errors.min",
+				"invalid": "{value} won't work",
+			}},
+			want: "Username:[minlen|needs 3 or more characters](needs 3 or more characters);" +
+				"Age:[min|@errors.min](@errors.min);" +
+				"Email:[taken|is taken](is taken);" +
+				"Nickname:[invalid|x won&#39;t work](x won&#39;t work);",
+		}, {
+			name: "translated catalog",
+			b: &Builder{
+				Messages:   map[string]string{"min": "@errors.min", "taken": "@errors.taken"},
+				Translator: MapTranslator{"fr": {"errors.min": "doit être au moins {min}", "errors.taken": "est déjà pris"}},
+				Locale:     "fr",
+			},
+			want: "Username:[minlen|must be at least 3 characters](must be at least 3 characters);" +
+				"Age:[min|doit être au moins 13](doit être au moins 13);" +
+				"Email:[taken|est déjà pris](est déjà pris);" +
+				"Nickname:[invalid|is invalid](is invalid);",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.b.InputTemplate = tpl
+			got, err := tc.b.Inputs(signup{}, errs...)
+			if err != nil {
+				t.Fatalf("Builder.Inputs() err = %v, want %v", err, nil)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Builder.Inputs() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
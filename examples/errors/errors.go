@@ -4,7 +4,6 @@ package main
 
 import (
 	"encoding/json"
-	"fmt"
 	"html/template"
 	"net/http"
 
@@ -58,41 +57,23 @@ func main() {
 		switch r.Method {
 		case http.MethodGet:
 			w.Header().Set("Content-Type", "text/html")
+			var errs form.Errors
+			errs.Add("Email", "is already taken")
+			errs.Add("Address.Street1", "is required")
+			errs.Add("Address.City", "is required")
+			errs.Add("Address.State", "must be a US state")
+			errs.Add("Address.Zip", "must be 5 digits")
+			errs.Add("Address.Zip", "is required")
 			data := struct {
 				Form   nestedForm
-				Errors []error
+				Errors form.Errors
 			}{
 				Form: nestedForm{
 					Name:    "Michael Scott",
 					Email:   "michael@dunder.com",
 					Address: nil,
 				},
-				Errors: []error{
-					fieldError{
-						Field: "Email",
-						Issue: "is already taken",
-					},
-					fieldError{
-						Field: "Address.Street1",
-						Issue: "is required",
-					},
-					fieldError{
-						Field: "Address.City",
-						Issue: "is required",
-					},
-					fieldError{
-						Field: "Address.State",
-						Issue: "must be a US state",
-					},
-					fieldError{
-						Field: "Address.Zip",
-						Issue: "must be 5 digits",
-					},
-					fieldError{
-						Field: "Address.Zip",
-						Issue: "is required",
-					},
-				},
+				Errors: errs,
 			}
 			err := pageTpl.Execute(w, data)
 			if err != nil {
@@ -109,14 +90,27 @@ func main() {
 		r.ParseForm()
 		dec := schema.NewDecoder()
 		dec.IgnoreUnknownKeys(true)
-		var form nestedForm
-		err := dec.Decode(&form, r.PostForm)
+		var nf nestedForm
+		err := dec.Decode(&nf, r.PostForm)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			// Render the form again with the user's input, along with an
+			// error next to each field that couldn't be decoded.
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadRequest)
+			data := struct {
+				Form   nestedForm
+				Errors form.Errors
+			}{
+				Form:   nf,
+				Errors: form.FromSchema(err),
+			}
+			if err := pageTpl.Execute(w, data); err != nil {
+				panic(err)
+			}
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
-		b, _ := json.Marshal(form)
+		b, _ := json.Marshal(nf)
 		w.Write(b)
 	})
 	http.ListenAndServe(":3000", nil)
@@ -135,17 +129,3 @@ type address struct {
 	State   string
 	Zip     string `form:"label=Postal Code"`
 }
-
-type fieldError struct {
-	Field string
-	Issue string
-}
-
-func (fe fieldError) Error() string {
-	return fmt.Sprintf("%v: %v", fe.Field, fe.Issue)
-}
-
-// You can implement this however you want - this is just how I'm doing it.
-func (fe fieldError) FieldError() (field, err string) {
-	return fe.Field, fe.Issue
-}
@@ -0,0 +1,80 @@
+package form
+
+import (
+	"strings"
+	"unicode"
+)
+
+// label returns the default label for a field with the Go name field.
+func (b *Builder) label(field string) string {
+	if b.LabelFunc != nil {
+		return b.LabelFunc(field)
+	}
+	return Humanize(field)
+}
+
+// Humanize converts a Go field name into a label that reads more like a
+// sentence, eg ZipCode becomes "Zip code" and Street1 becomes "Street 1".
+// Acronyms are left as is, so APIKey becomes "API key". This is the
+// default LabelFunc for the Builder.
+func Humanize(field string) string {
+	var words []string
+	runes := []rune(field)
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && !wordBoundary(runes, i) {
+			continue
+		}
+		if word := strings.Trim(string(runes[start:i]), "_"); word != "" {
+			words = append(words, word)
+		}
+		start = i
+	}
+	for i, word := range words {
+		if isAcronym(word) {
+			continue
+		}
+		lower := strings.ToLower(word)
+		if i == 0 {
+			r := []rune(lower)
+			r[0] = unicode.ToUpper(r[0])
+			lower = string(r)
+		}
+		words[i] = lower
+	}
+	return strings.Join(words, " ")
+}
+
+// wordBoundary returns true if a new word starts at runes[i]. Words start
+// at underscores, at an upper case letter following a lower case letter or
+// digit, at the last upper case letter of an acronym followed by a lower
+// case letter (eg the K in APIKey), and where digits begin or end.
+func wordBoundary(runes []rune, i int) bool {
+	prev, r := runes[i-1], runes[i]
+	switch {
+	case r == '_' || prev == '_':
+		return true
+	case unicode.IsDigit(r) != unicode.IsDigit(prev):
+		return true
+	case unicode.IsUpper(r) && unicode.IsLower(prev):
+		return true
+	case unicode.IsUpper(r) && unicode.IsUpper(prev):
+		return i+1 < len(runes) && unicode.IsLower(runes[i+1])
+	}
+	return false
+}
+
+// isAcronym returns true if word has more than one letter and all of its
+// letters are upper case, eg ID or URL.
+func isAcronym(word string) bool {
+	var letters int
+	for _, r := range word {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters > 1
+}
@@ -0,0 +1,99 @@
+package form
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHumanize(t *testing.T) {
+	tests := map[string]string{
+		"Name":         "Name",
+		"ZipCode":      "Zip code",
+		"Street1":      "Street 1",
+		"APIKey":       "API key",
+		"UserID":       "User ID",
+		"ID":           "ID",
+		"HTTPServer":   "HTTP server",
+		"Address2Line": "Address 2 line",
+		"first_name":   "First name",
+		"":             "",
+	}
+	for arg, want := range tests {
+		if got := Humanize(arg); got != want {
+			t.Errorf("Humanize(%q) = %q, want %q", arg, got, want)
+		}
+	}
+}
+
+func Test_fields_labels(t *testing.T) {
+	arg := struct {
+		ZipCode string
+		Email   string `form:"label=Email address"`
+		Phone   string `form:"placeholder=555-5555"`
+		Prefs   map[string]string
+		Billing struct {
+			Street1 string
+		}
+	}{
+		Prefs: map[string]string{"darkMode": "on"},
+	}
+	type meta struct {
+		Label, Placeholder string
+	}
+	tests := []struct {
+		name string
+		b    *Builder
+		want []meta
+	}{
+		{
+			name: "default",
+			b:    &Builder{},
+			want: []meta{
+				{"Zip code", "Zip code"},
+				{"Email address", "Email address"},
+				{"Phone", "555-5555"},
+				{"darkMode", "darkMode"},
+				{"Street 1", "Street 1"},
+			},
+		}, {
+			name: "label func",
+			b:    &Builder{LabelFunc: strings.ToUpper},
+			want: []meta{
+				{"ZIPCODE", "ZIPCODE"},
+				{"Email address", "Email address"},
+				{"PHONE", "555-5555"},
+				{"darkMode", "darkMode"},
+				{"STREET1", "STREET1"},
+			},
+		}, {
+			name: "explicit placeholders",
+			b:    &Builder{ExplicitPlaceholders: true},
+			want: []meta{
+				{"Zip code", ""},
+				{"Email address", ""},
+				{"Phone", "555-5555"},
+				{"darkMode", ""},
+				{"Street 1", ""},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fields, err := tc.b.fields(arg)
+			if err != nil {
+				t.Fatalf("fields() err = %v, want %v", err, nil)
+			}
+			var got []meta
+			for _, f := range fields {
+				got = append(got, meta{f.Label, f.Placeholder})
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("fields() = %+v, want %+v", got, tc.want)
+			}
+			if g := fields[len(fields)-1].Groups[0].Label; g != tc.b.label("Billing") {
+				t.Errorf("Group.Label = %q, want %q", g, tc.b.label("Billing"))
+			}
+		})
+	}
+}
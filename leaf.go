@@ -0,0 +1,71 @@
+package form
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+)
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// isLeaf returns true if t is a struct type that should be rendered as a
+// single input rather than having each of its fields rendered. This
+// includes time.Time, the sql.Null* types, any type implementing
+// encoding.TextMarshaler, any types in the Builder's LeafTypes, and any
+// types with a Codec, since the Codec is what formats and parses them.
+func (b *Builder) isLeaf(t reflect.Type) bool {
+	if t == timeReflectType || isSQLNull(t) {
+		return true
+	}
+	if _, ok := b.Codecs[t]; ok {
+		return true
+	}
+	if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return true
+	}
+	for _, lt := range b.LeafTypes {
+		if t == lt {
+			return true
+		}
+	}
+	return false
+}
+
+// isSQLNull returns true if t is one of the sql.Null* types, such as
+// sql.NullString or sql.NullInt64. These all have the same shape - a value
+// followed by a Valid bool - so we check for that rather than importing
+// database/sql.
+func isSQLNull(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct &&
+		t.PkgPath() == "database/sql" &&
+		strings.HasPrefix(t.Name(), "Null") &&
+		t.NumField() == 2 &&
+		t.Field(1).Name == "Valid" &&
+		t.Field(1).Type.Kind() == reflect.Bool
+}
+
+// leafValue returns the value that should be provided to the InputTemplate
+// as the Value of a leaf field. Leaf types don't print nicely, so their
+// formatted value is used instead, unless it is empty in which case nil is
+// returned so that templates render them as empty. Valid sql.Null* values
+// are unwrapped so that a sql.NullInt64 has the same value as an int64,
+// and leaf types without a Codec are left as is.
+func (b *Builder) leafValue(rv reflect.Value, inputType string) interface{} {
+	if b.codec(rv.Type(), inputType) == nil {
+		return rv.Interface()
+	}
+	if isSQLNull(rv.Type()) {
+		if !rv.Field(1).Bool() {
+			return nil
+		}
+		if inner := rv.Field(0); inner.Kind() != reflect.Struct {
+			return inner.Interface()
+		}
+		return b.leafValue(rv.Field(0), inputType)
+	}
+	if str := b.format(rv, inputType); str != "" {
+		return str
+	}
+	return nil
+}
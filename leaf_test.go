@@ -0,0 +1,123 @@
+package form
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type testMoney struct {
+	cents int64
+}
+
+func (m testMoney) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%02d", m.cents/100, m.cents%100)), nil
+}
+
+func (m *testMoney) UnmarshalText(text []byte) error {
+	f, err := strconv.ParseFloat(string(text), 64)
+	if err != nil {
+		return err
+	}
+	m.cents = int64(f*100 + 0.5)
+	return nil
+}
+
+type testPoint struct {
+	X, Y int
+}
+
+func Test_fields_leaves(t *testing.T) {
+	when := time.Date(2019, 3, 14, 15, 9, 26, 0, time.UTC)
+	b := &Builder{
+		LeafTypes: []reflect.Type{reflect.TypeOf(testPoint{})},
+	}
+	got, err := b.fields(struct {
+		CreatedAt time.Time
+		Birthday  time.Time `form:"type=date"`
+		DeletedAt *time.Time
+		Price     testMoney
+		Nickname  sql.NullString
+		Age       sql.NullInt64
+		Rating    sql.NullFloat64
+		Location  testPoint
+	}{
+		CreatedAt: when,
+		Birthday:  when,
+		Price:     testMoney{1234},
+		Age:       sql.NullInt64{Int64: 42, Valid: true},
+		Location:  testPoint{1, 2},
+	})
+	if err != nil {
+		t.Fatalf("fields() err = %v, want %v", err, nil)
+	}
+	want := []struct {
+		Name  string
+		Type  string
+		Value interface{}
+	}{
+		{"CreatedAt", "datetime-local", "2019-03-14T15:09"},
+		{"Birthday", "date", "2019-03-14"},
+		{"DeletedAt", "datetime-local", nil},
+		{"Price", "text", "12.34"},
+		{"Nickname", "text", nil},
+		{"Age", "number", int64(42)},
+		{"Rating", "number", nil},
+		{"Location", "text", testPoint{1, 2}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("len(fields()) = %d, want %d; fields = %+v", len(got), len(want), got)
+	}
+	for i, f := range got {
+		if f.Name != want[i].Name || f.Type != want[i].Type || !reflect.DeepEqual(f.Value, want[i].Value) {
+			t.Errorf("fields()[%d] = {%v %v %#v}, want %+v", i, f.Name, f.Type, f.Value, want[i])
+		}
+	}
+}
+
+func TestBuilder_Decode_leaves(t *testing.T) {
+	type leaves struct {
+		CreatedAt time.Time
+		Birthday  time.Time
+		DeletedAt *time.Time
+		Price     testMoney
+		Nickname  sql.NullString
+		Age       sql.NullInt64
+		Seen      sql.NullTime
+	}
+	var b Builder
+	var got leaves
+	err := b.Decode(&got, url.Values{
+		"CreatedAt": {"2019-03-14T15:09"},
+		"Birthday":  {"2019-03-14"},
+		"DeletedAt": {"2019-03-14T15:09:26Z"},
+		"Price":     {"12.34"},
+		"Nickname":  {""},
+		"Age":       {"42"},
+		"Seen":      {"2019-03-14"},
+	})
+	if err != nil {
+		t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+	}
+	deleted := time.Date(2019, 3, 14, 15, 9, 26, 0, time.UTC)
+	want := leaves{
+		CreatedAt: time.Date(2019, 3, 14, 15, 9, 0, 0, time.UTC),
+		Birthday:  time.Date(2019, 3, 14, 0, 0, 0, 0, time.UTC),
+		DeletedAt: &deleted,
+		Price:     testMoney{1234},
+		Age:       sql.NullInt64{Int64: 42, Valid: true},
+		Seen:      sql.NullTime{Time: time.Date(2019, 3, 14, 0, 0, 0, 0, time.UTC), Valid: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Builder.Decode() = %+v, want %+v", got, want)
+	}
+
+	err = b.Decode(&got, url.Values{"Birthday": {"not a date"}})
+	if err == nil {
+		t.Errorf("Builder.Decode() err = nil, want an error")
+	}
+}
@@ -0,0 +1,163 @@
+package form
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Naming determines how the names of nested fields, slice elements, and
+// map entries are joined together to build the name of each input.
+type Naming int
+
+const (
+	// DotNaming separates each name with a period, eg Address.Street1 or
+	// Items.0.Name. This is the default, and it is what the gorilla/schema
+	// package expects.
+	DotNaming Naming = iota
+	// BracketNaming wraps each name after the first in brackets, eg
+	// Address[Street1] or Items[0][Name], which is what Rails and PHP
+	// backends expect.
+	BracketNaming
+)
+
+// SnakeCase converts a Go field name to snake_case, eg ZipCode becomes
+// zip_code and APIKey becomes api_key. Digits are kept with the word
+// before them, so Street1 becomes street1. It is intended to be used as
+// the Builder's NameFunc.
+func SnakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// fieldName returns the name of sf as it is used in the names of inputs,
+// not counting any name tag. The first of the Builder's NameTags present
+// on the field is used, then the NameFunc, and then the Go field name.
+func (b *Builder) fieldName(sf reflect.StructField) string {
+	if name, ok := b.tagName(sf); ok {
+		return name
+	}
+	if b.NameFunc != nil {
+		return b.NameFunc(sf.Name)
+	}
+	return sf.Name
+}
+
+// tagName returns the name set for sf by the first of the Builder's
+// NameTags that it has, eg `json:"zip_code,omitempty"`. Tags without a
+// name, like `json:",omitempty"` or `json:"-"`, are skipped.
+func (b *Builder) tagName(sf reflect.StructField) (string, bool) {
+	for _, key := range b.NameTags {
+		name := strings.TrimSpace(strings.Split(sf.Tag.Get(key), ",")[0])
+		if name != "" && name != "-" {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// containerName returns the name of a slice, map, or nested struct field
+// as it should be used in the names of its elements, along with the tags
+// that should be applied to each element. The tags of the container are
+// used for every element, except for the name tag which replaces the
+// container's name.
+func (b *Builder) containerName(sf reflect.StructField, tags map[string]string) (string, map[string]string) {
+	v, ok := tags["name"]
+	if !ok {
+		return b.fieldName(sf), tags
+	}
+	etags := make(map[string]string, len(tags))
+	for k, v := range tags {
+		if k != "name" {
+			etags[k] = v
+		}
+	}
+	return v, etags
+}
+
+// bracketIndexes returns true if slice indexes are wrapped in brackets.
+func (b *Builder) bracketIndexes() bool {
+	return b.BracketIndexes || b.Naming == BracketNaming
+}
+
+// indexName returns the name used for the element at index i of a slice.
+func (b *Builder) indexName(i int) string {
+	if b.bracketIndexes() {
+		return "[" + strconv.Itoa(i) + "]"
+	}
+	return strconv.Itoa(i)
+}
+
+// joinNames joins the names leading up to a field using the Builder's
+// Naming. Bracketed indexes, eg [0], are always appended as is.
+func (b *Builder) joinNames(names []string) string {
+	var sb strings.Builder
+	for i, name := range names {
+		switch {
+		case i == 0 || strings.HasPrefix(name, "["):
+			sb.WriteString(name)
+		case b.Naming == BracketNaming:
+			sb.WriteString("[" + name + "]")
+		default:
+			sb.WriteString("." + name)
+		}
+	}
+	return sb.String()
+}
+
+// nextName returns the name that follows prefix in name, eg Street1 for a
+// name of Address.Street1 (or Address[Street1]) and a prefix of Address.
+// ok is false if name isn't nested inside of prefix, or if whole is false
+// and there isn't anything nested in the next name. When whole is true,
+// the entire remainder of the name is returned instead, which is how map
+// keys containing a period are handled.
+func (b *Builder) nextName(name, prefix string, whole bool) (next string, ok bool) {
+	rest := name
+	if prefix != "" {
+		sep := "."
+		if b.Naming == BracketNaming {
+			sep = "["
+		}
+		if !strings.HasPrefix(name, prefix+sep) {
+			return "", false
+		}
+		rest = name[len(prefix)+1:]
+	}
+	if rest == "" {
+		return "", false
+	}
+
+	bracketed := prefix != "" && b.Naming == BracketNaming
+	if whole {
+		if bracketed {
+			if !strings.HasSuffix(rest, "]") || len(rest) == 1 {
+				return "", false
+			}
+			return rest[:len(rest)-1], true
+		}
+		return rest, true
+	}
+	end := strings.IndexAny(rest, ".[")
+	if bracketed {
+		end = strings.Index(rest, "]")
+		if end >= 0 && end == len(rest)-1 {
+			end = -1
+		}
+	}
+	if end <= 0 {
+		return "", false
+	}
+	return rest[:end], true
+}
@@ -0,0 +1,118 @@
+package form
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Name":       "name",
+		"ZipCode":    "zip_code",
+		"Street1":    "street1",
+		"APIKey":     "api_key",
+		"UserID":     "user_id",
+		"HTTPServer": "http_server",
+		"already_ok": "already_ok",
+	}
+	for arg, want := range tests {
+		if got := SnakeCase(arg); got != want {
+			t.Errorf("SnakeCase(%q) = %q, want %q", arg, got, want)
+		}
+	}
+}
+
+type namingItem struct {
+	Name string
+	Qty  int
+}
+
+type namingForm struct {
+	FullName  string
+	ZipCode   string `json:"zip,omitempty"`
+	Email     string `json:"-" form:"name=email_address"`
+	Address   struct{ Street1 string }
+	Items     []namingItem
+	Prefs     map[string]string
+	Addresses map[string]struct{ City string }
+}
+
+func TestBuilder_naming(t *testing.T) {
+	arg := namingForm{
+		FullName:  "Michael Scott",
+		ZipCode:   "18503",
+		Email:     "michael@dundermifflin.com",
+		Items:     []namingItem{{"Paper", 10}},
+		Prefs:     map[string]string{"font.size": "12"},
+		Addresses: map[string]struct{ City string }{"work": {"Scranton"}},
+	}
+	tests := []struct {
+		name string
+		b    *Builder
+		want []string
+	}{
+		{
+			name: "dot",
+			b:    &Builder{},
+			want: []string{
+				"FullName", "ZipCode", "email_address", "Address.Street1",
+				"Items.0.Name", "Items.0.Qty", "Prefs.font.size", "Addresses.work.City",
+			},
+		}, {
+			name: "dot with bracket indexes",
+			b:    &Builder{BracketIndexes: true},
+			want: []string{
+				"FullName", "ZipCode", "email_address", "Address.Street1",
+				"Items[0].Name", "Items[0].Qty", "Prefs.font.size", "Addresses.work.City",
+			},
+		}, {
+			name: "bracket",
+			b:    &Builder{Naming: BracketNaming},
+			want: []string{
+				"FullName", "ZipCode", "email_address", "Address[Street1]",
+				"Items[0][Name]", "Items[0][Qty]", "Prefs[font.size]", "Addresses[work][City]",
+			},
+		}, {
+			name: "snake case with json tags",
+			b:    &Builder{NameFunc: SnakeCase, NameTags: []string{"json"}},
+			want: []string{
+				"full_name", "zip", "email_address", "address.street1",
+				"items.0.name", "items.0.qty", "prefs.font.size", "addresses.work.city",
+			},
+		}, {
+			name: "bracket snake case",
+			b:    &Builder{Naming: BracketNaming, NameFunc: SnakeCase},
+			want: []string{
+				"full_name", "zip_code", "email_address", "address[street1]",
+				"items[0][name]", "items[0][qty]", "prefs[font.size]", "addresses[work][city]",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fields, err := tc.b.Fields(arg)
+			if err != nil {
+				t.Fatalf("Builder.Fields() err = %v, want %v", err, nil)
+			}
+			var got []string
+			values := url.Values{}
+			for _, f := range fields {
+				got = append(got, f.Name)
+				values.Set(f.Name, f.Formatted)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Builder.Fields() names = %v, want %v", got, tc.want)
+			}
+
+			// Anything rendered should decode back into the same value.
+			var decoded namingForm
+			if err := tc.b.Decode(&decoded, values); err != nil {
+				t.Fatalf("Builder.Decode() err = %v, want %v", err, nil)
+			}
+			if !reflect.DeepEqual(decoded, arg) {
+				t.Errorf("Builder.Decode() = %+v, want %+v", decoded, arg)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package form
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Option is a single choice for a field that is rendered as a select,
+// radio group, or similar input. Options are made available to the
+// InputTemplate via the field's Options, eg:
+//
+//   <select name="{{.Name}}">
+//     {{range .Options}}
+//       <option value="{{.Value}}"{{if .Selected}} selected{{end}}{{if .Disabled}} disabled{{end}}>{{.Label}}</option>
+//     {{end}}
+//   </select>
+type Option struct {
+	Value    string
+	Label    string
+	Disabled bool
+	// Group can be used to render an <optgroup> around related options.
+	Group string
+	// Selected is set by the Builder when the option's Value matches the
+	// field's current value. It does not need to be set by an Optioner.
+	Selected bool
+}
+
+// Optioner can be implemented by the type of a struct field in order to
+// provide the options for that field. This is most useful for enum types,
+// as every form using the type will render the same choices. Eg:
+//
+//   type Status string
+//
+//   func (Status) Options() []form.Option {
+//     return []form.Option{
+//       {Value: "draft", Label: "Draft"},
+//       {Value: "published", Label: "Published"},
+//     }
+//   }
+//
+// Fields with options default to the "select" type, but this can be
+// changed with a type tag, eg `form:"type=radio"`.
+//
+// Options can also be provided via the options tag, which takes a comma
+// separated list of value:label pairs and takes precedence over an
+// Optioner, eg `form:"options=draft:Draft,published:Published"`.
+type Optioner interface {
+	Options() []Option
+}
+
+// optionsOf returns the options for rv if its type, or a pointer to its
+// type, implements the Optioner interface.
+func optionsOf(rv reflect.Value) []Option {
+	if !rv.IsValid() {
+		return nil
+	}
+	if o, ok := rv.Interface().(Optioner); ok {
+		return o.Options()
+	}
+	if reflect.PtrTo(rv.Type()).Implements(reflect.TypeOf((*Optioner)(nil)).Elem()) {
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		return ptr.Interface().(Optioner).Options()
+	}
+	return nil
+}
+
+// parseOptions parses the value of an options tag. Each option is
+// separated by a comma and is either value:label or just a value, in
+// which case the value is also used as the label.
+func parseOptions(tag string) []Option {
+	var ret []Option
+	for _, opt := range strings.Split(tag, ",") {
+		opt = strings.TrimSpace(opt)
+		if opt == "" {
+			continue
+		}
+		vl := strings.SplitN(opt, ":", 2)
+		o := Option{
+			Value: strings.TrimSpace(vl[0]),
+			Label: strings.TrimSpace(vl[0]),
+		}
+		if len(vl) == 2 {
+			o.Label = strings.TrimSpace(vl[1])
+		}
+		ret = append(ret, o)
+	}
+	return ret
+}
+
+// selectOptions marks each option whose value matches the provided
+// formatted value as selected. The options slice is copied so that options
+// returned by an Optioner are never modified.
+func selectOptions(opts []Option, value string) []Option {
+	if len(opts) == 0 {
+		return opts
+	}
+	ret := make([]Option, len(opts))
+	copy(ret, opts)
+	for i := range ret {
+		ret[i].Selected = ret[i].Value == value
+	}
+	return ret
+}
@@ -0,0 +1,135 @@
+package form
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testStatus string
+
+func (testStatus) Options() []Option {
+	return []Option{
+		{Value: "draft", Label: "Draft"},
+		{Value: "published", Label: "Published"},
+		{Value: "archived", Label: "Archived", Disabled: true, Group: "Old"},
+	}
+}
+
+type testColor int
+
+func (*testColor) Options() []Option {
+	return []Option{
+		{Value: "0", Label: "Red"},
+		{Value: "1", Label: "Blue"},
+	}
+}
+
+func Test_fields_options(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  interface{}
+		want []Field
+	}{
+		{
+			name: "options tag",
+			arg: struct {
+				Status string `form:"options=draft:Draft, published:Published,other"`
+			}{"published"},
+			want: []Field{
+				{
+					Name:        "Status",
+					Label:       "Status",
+					Placeholder: "Status",
+					Type:        "select",
+					Value:       "published",
+					Formatted:   "published",
+					Options: []Option{
+						{Value: "draft", Label: "Draft"},
+						{Value: "published", Label: "Published", Selected: true},
+						{Value: "other", Label: "other"},
+					},
+				},
+			},
+		}, {
+			name: "optioner",
+			arg: struct {
+				Status testStatus
+			}{"draft"},
+			want: []Field{
+				{
+					Name:        "Status",
+					Label:       "Status",
+					Placeholder: "Status",
+					Type:        "select",
+					Value:       testStatus("draft"),
+					Formatted:   "draft",
+					Options: []Option{
+						{Value: "draft", Label: "Draft", Selected: true},
+						{Value: "published", Label: "Published"},
+						{Value: "archived", Label: "Archived", Disabled: true, Group: "Old"},
+					},
+				},
+			},
+		}, {
+			name: "pointer optioner with type tag",
+			arg: struct {
+				Color testColor `form:"type=radio"`
+			}{1},
+			want: []Field{
+				{
+					Name:        "Color",
+					Label:       "Color",
+					Placeholder: "Color",
+					Type:        "radio",
+					Value:       testColor(1),
+					Formatted:   "1",
+					Options: []Option{
+						{Value: "0", Label: "Red"},
+						{Value: "1", Label: "Blue", Selected: true},
+					},
+				},
+			},
+		}, {
+			name: "tag overrides optioner",
+			arg: struct {
+				Status testStatus `form:"options=draft"`
+			}{"draft"},
+			want: []Field{
+				{
+					Name:        "Status",
+					Label:       "Status",
+					Placeholder: "Status",
+					Type:        "select",
+					Value:       testStatus("draft"),
+					Formatted:   "draft",
+					Options: []Option{
+						{Value: "draft", Label: "draft", Selected: true},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b Builder
+			got, err := b.fields(tc.arg)
+			if err != nil {
+				t.Fatalf("fields() err = %v, want %v", err, nil)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("fields(%+v) = %+v, want %+v", tc.arg, got, tc.want)
+			}
+		})
+	}
+}
+
+func Test_selectOptions(t *testing.T) {
+	opts := testStatus("").Options()
+	got := selectOptions(opts, "published")
+	if !got[1].Selected {
+		t.Errorf("selectOptions() = %+v, want published selected", got)
+	}
+	if opts[1].Selected {
+		t.Errorf("selectOptions() modified the provided options")
+	}
+}
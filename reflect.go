@@ -1,84 +1,226 @@
 package form
 
 import (
+	"fmt"
 	"html/template"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 )
 
-// valueOf is basically just reflect.ValueOf, but if the Kind() of the
-// value is a pointer or interface it will try to get the reflect.Value
-// of the underlying element, and if the pointer is nil it will
-// create a new instance of the type and return the reflect.Value of it.
+// indirect is used to get the underlying element of rv. If the Kind() of
+// the value is a pointer or interface it will try to get the reflect.Value
+// of the underlying element, and if the pointer is nil it will create a
+// new instance of the type and return the reflect.Value of it.
 //
-// This is used to make the rest of the fields function simpler.
-func valueOf(v interface{}) reflect.Value {
-	rv := reflect.ValueOf(v)
-	// If a nil pointer is passed in but has a type we can recover, but I
-	// really should just panic and tell people to fix their shitty code.
-	if rv.Type().Kind() == reflect.Ptr && rv.IsNil() {
-		rv = reflect.New(rv.Type().Elem()).Elem()
-	}
-	// If we have a pointer or interface let's try to get the underlying
-	// element
+// This is used to make the rest of the fields function simpler. An
+// *InvalidValueError is returned if rv is nil, as there is no type we can
+// recover in that case.
+func indirect(rv reflect.Value) (reflect.Value, error) {
+	// If a nil pointer is passed in it has a type we can recover, so we
+	// create a new instance of it.
 	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.Kind() == reflect.Ptr && rv.IsNil() {
+			rv = reflect.New(rv.Type().Elem()).Elem()
+			continue
+		}
 		rv = rv.Elem()
 	}
-	return rv
+	if !rv.IsValid() {
+		return rv, &InvalidValueError{Reason: "value is nil"}
+	}
+	return rv, nil
+}
+
+// fields walks v, which must be a struct or a map with string keys, and
+// returns a field for each input that should be rendered. Values that
+// can't be rendered result in an *UnsupportedTypeError or an
+// *InvalidValueError rather than a panic, since fields is typically
+// called from inside of a template.
+func (b *Builder) fields(v interface{}, names ...string) ([]Field, error) {
+	return b.walk(reflect.ValueOf(v), names, nil, nil)
 }
 
-func fields(v interface{}, names ...string) []field {
-	rv := valueOf(v)
+// walk does the real work for fields. groups are the nested structs that
+// lead up to v, and are added to each field so templates can tell which
+// struct a field belongs to. parents are the types of every struct and map
+// that lead up to v, and are used to detect cycles and limit the depth.
+func (b *Builder) walk(rv reflect.Value, names []string, groups []Group, parents []reflect.Type) ([]Field, error) {
+	rv, err := indirect(rv)
+	if err != nil {
+		return nil, err
+	}
+	if len(parents) >= b.maxDepth() {
+		return nil, &DepthError{Path: b.joinNames(names), MaxDepth: b.maxDepth()}
+	}
+	// parents is capped for the same reason groups is below.
+	parents = append(parents[:len(parents):len(parents)], rv.Type())
+	if isMap(rv.Type()) {
+		return b.mapFields(reflect.StructField{}, rv, map[string]string{}, names, groups, parents)
+	}
 	if rv.Kind() != reflect.Struct {
-		// We can't really do much with a non-struct type. I suppose this
-		// could eventually support maps as well, but for now it does not.
-		panic("invalid value; only structs are supported")
+		// We can't really do much with other types, like a slice, as they
+		// don't have names for their inputs.
+		return nil, &UnsupportedTypeError{Path: b.joinNames(names), Type: rv.Type()}
 	}
 
-	t := rv.Type()
-	ret := make([]field, 0, t.NumField())
-	for i := 0; i < t.NumField(); i++ {
-		rf := rv.Field(i)
-		// If this is a nil pointer, create a new instance of the element.
-		// This could probably be done in a simpler way given that we
-		// typically recur with this value, but this works so I'm letting it
-		// be.
-		if t.Field(i).Type.Kind() == reflect.Ptr && rf.IsNil() {
-			rf = reflect.New(t.Field(i).Type.Elem()).Elem()
+	sfs := b.structFields(rv.Type())
+	ret := make([]Field, 0, len(sfs))
+	for _, sf := range sfs {
+		// Fields promoted from a nil embedded pointer are treated as if
+		// they were empty.
+		orig, ok := fieldByIndex(rv, sf.Index)
+		if !ok {
+			orig = reflect.Zero(sf.Type)
+		}
+		tags := sf.tags
+
+		rf := orig
+		// If this is a pointer, get the element it points to so we can
+		// tell whether it is a nested struct. Nil pointers get a new
+		// instance of the element instead, which means a nil *Address is
+		// rendered the same as an empty Address.
+		for rf.Kind() == reflect.Ptr {
+			if rf.IsNil() {
+				rf = reflect.New(rf.Type().Elem()).Elem()
+				continue
+			}
+			rf = rf.Elem()
 		}
 
 		// If this is a struct it has nested fields we need to add. The
-		// simplest way to do this is to recursively call `fields` but
+		// simplest way to do this is to recursively call `walk` but
 		// to provide the name of this struct field to be added as a prefix
-		// to the fields.
-		if rf.Kind() == reflect.Struct {
-			ret = append(ret, fields(rf.Interface(), append(names, t.Field(i).Name)...)...)
+		// to the fields. A name tag replaces the prefix, and the label or
+		// legend tag is used as the title of the group.
+		//
+		// Leaf types like time.Time are structs, but they represent a single
+		// value so they are rendered as a single input instead.
+		if rf.Kind() == reflect.Struct && !b.isLeaf(rf.Type()) {
+			name, _ := b.containerName(sf.StructField, tags)
+			path := append(names, name)
+			// A nil pointer to a type we are already inside of, like a
+			// Parent *Category field in a Category, would have us
+			// allocating and rendering new values forever.
+			if orig.Kind() == reflect.Ptr && orig.IsNil() && hasType(parents, rf.Type()) {
+				return nil, &CycleError{Path: b.joinNames(path), Type: rf.Type()}
+			}
+			nested, err := b.walk(rf, path, b.group(sf.StructField, tags, path, groups), parents)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, nested...)
 			continue
 		}
 
 		// If we are still in this loop then we aren't dealing with a nested
-		// struct and need to add the field. First we check to see if the
-		// ignore tag is present, then we set default values, then finally
-		// we overwrite defaults with any provided tags.
-		tags := parseTags(t.Field(i).Tag.Get("form"))
-		if _, ok := tags["-"]; ok {
+		// struct and need to add the field. We set default values, then
+		// overwrite defaults with any provided tags.
+
+		// Slices and arrays are expanded into one or more fields for each
+		// element.
+		if isRepeated(rf.Type()) {
+			name, etags := b.containerName(sf.StructField, tags)
+			efs, err := b.repeatedFields(sf.StructField, rf, etags, append(names, name), groups, parents)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, efs...)
+			continue
+		}
+
+		// Maps are expanded into one or more fields for each key.
+		if isMap(rf.Type()) {
+			name, etags := b.containerName(sf.StructField, tags)
+			efs, err := b.mapFields(sf.StructField, rf, etags, append(names, name), groups, parents)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, efs...)
 			continue
 		}
-		name := append(names, t.Field(i).Name)
-		f := field{
-			Name:        strings.Join(name, "."),
-			Label:       t.Field(i).Name,
-			Placeholder: t.Field(i).Name,
-			Type:        "text",
-			Value:       rv.Field(i).Interface(),
+
+		f, err := b.field(sf.StructField, orig, tags, append(names, b.fieldName(sf.StructField)))
+		if err != nil {
+			return nil, err
 		}
-		applyTags(&f, tags)
+		f.Groups = groups
 		ret = append(ret, f)
 	}
-	return ret
+	return ret, nil
 }
 
-func applyTags(f *field, tags map[string]string) {
+// field builds a single field for the value rv. sf is used to determine
+// the default label and input type, and path is every name that leads up
+// to the field, including the field's own name.
+//
+// Values that can't be represented by an input, like channels, funcs, and
+// maps without string keys, result in an *UnsupportedTypeError.
+func (b *Builder) field(sf reflect.StructField, rv reflect.Value, tags map[string]string, path []string) (Field, error) {
+	switch elemType(rv.Type()).Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return Field{}, &UnsupportedTypeError{Path: b.joinNames(path), Type: rv.Type()}
+	}
+	if b.unsupportedMap(rv.Type()) {
+		return Field{}, &UnsupportedTypeError{Path: b.joinNames(path), Type: rv.Type()}
+	}
+	// Pointers are dereferenced so that templates get the value rather
+	// than an address. A nil pointer has a nil Value so that it renders as
+	// empty, which is how an optional *int with no value is told apart
+	// from one set to 0. The zero value of the element is still used to
+	// look up options.
+	ev, isNil := rv, false
+	for ev.Kind() == reflect.Ptr {
+		if ev.IsNil() {
+			ev, isNil = reflect.Zero(ev.Type().Elem()), true
+			continue
+		}
+		ev = ev.Elem()
+	}
+	f := Field{
+		Name:        b.joinNames(path),
+		Label:       b.label(sf.Name),
+		Placeholder: b.label(sf.Name),
+		Type:        b.inputType(sf),
+		Value:       ev.Interface(),
+		Options:     optionsOf(ev),
+	}
+	tags = b.translateTags(tags)
+	if err := applyTags(&f, tags); err != nil {
+		return Field{}, err
+	}
+	if _, ok := tags["placeholder"]; !ok && b.ExplicitPlaceholders {
+		f.Placeholder = ""
+	}
+	// We format values after applying tags because the format of a
+	// time depends on the type of input, eg date vs time.
+	f.Formatted = b.format(rv, f.Type)
+	switch {
+	case isNil:
+		f.Value = nil
+	case ev.Kind() == reflect.Struct && b.isLeaf(ev.Type()):
+		f.Value = b.leafValue(ev, f.Type)
+	}
+	// Floats need a step, otherwise browsers only accept whole numbers.
+	if _, ok := tags["step"]; !ok && f.Type == "number" {
+		if k := valueType(sf.Type).Kind(); k == reflect.Float32 || k == reflect.Float64 {
+			f.Step = "any"
+		}
+	}
+	// Options are copied by selectOptions, so they can be translated
+	// without modifying those returned by an Optioner.
+	f.Options = selectOptions(f.Options, f.Formatted)
+	for i := range f.Options {
+		f.Options[i].Label = b.translate(f.Options[i].Label)
+	}
+	return f, nil
+}
+
+// applyTags sets the values of f provided via tags. An error is returned
+// if a length rule isn't a whole number, just like Validate does for the
+// other rules.
+func applyTags(f *Field, tags map[string]string) error {
 	if v, ok := tags["name"]; ok {
 		f.Name = v
 	}
@@ -97,10 +239,50 @@ func applyTags(f *field, tags map[string]string) {
 	if v, ok := tags["id"]; ok {
 		f.ID = v
 	}
+	if v, ok := tags["template"]; ok {
+		f.Template = v
+	}
 	if v, ok := tags["footer"]; ok {
 		// Probably shouldn't be HTML but whatever.
 		f.Footer = template.HTML(v)
 	}
+	if _, ok := tags["required"]; ok {
+		f.Required = true
+	}
+	if v, ok := tags["min"]; ok {
+		f.Min = v
+	}
+	if v, ok := tags["max"]; ok {
+		f.Max = v
+	}
+	if v, ok := tags["minlen"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("form: invalid minlen for field %v: %w", f.Name, err)
+		}
+		f.MinLength = n
+	}
+	if v, ok := tags["maxlen"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("form: invalid maxlen for field %v: %w", f.Name, err)
+		}
+		f.MaxLength = n
+	}
+	if v, ok := tags["pattern"]; ok {
+		f.Pattern = v
+	}
+	if v, ok := tags["step"]; ok {
+		f.Step = v
+	}
+	if v, ok := tags["options"]; ok {
+		f.Options = parseOptions(v)
+	}
+	// Fields with options are rendered as a select unless told otherwise.
+	if _, ok := tags["type"]; !ok && len(f.Options) > 0 {
+		f.Type = "select"
+	}
+	return nil
 }
 
 func parseTags(tags string) map[string]string {
@@ -111,13 +293,19 @@ func parseTags(tags string) map[string]string {
 	split := strings.Split(tags, ";")
 	ret := make(map[string]string, len(split))
 	for _, tag := range split {
-		kv := strings.Split(tag, "=")
+		// Values like patterns may contain an =, so only split on the first.
+		kv := strings.SplitN(tag, "=", 2)
 		if len(kv) < 2 {
-			if kv[0] == "-" {
+			k := strings.TrimSpace(kv[0])
+			if k == "-" {
 				return map[string]string{
 					"-": "this field is ignored",
 				}
 			}
+			// Tags without a value, like required, are flags.
+			if k != "" {
+				ret[k] = ""
+			}
 			continue
 		}
 		k, v := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
@@ -126,12 +314,344 @@ func parseTags(tags string) map[string]string {
 	return ret
 }
 
-type field struct {
+// Field is all of the information about a single input that is provided
+// to the InputTemplate when rendering it. Fields are typically built and
+// rendered by the Builder's Inputs method, but they can also be retrieved
+// via its Fields method in order to create custom layouts.
+type Field struct {
 	Name        string
 	Label       string
 	Placeholder string
 	Type        string
 	ID          string
 	Value       interface{}
-	Footer      template.HTML
+	// Formatted is the Value formatted as a string using the Codec for its
+	// type. This is typically what should be used for an input's value
+	// attribute.
+	Formatted string
+	Footer    template.HTML
+	// Template is the name of the template used to render this field, as
+	// set by the template tag. See the Builder type for more info.
+	Template string
+
+	// Validation rules. These are set via the required, min, max, minlen,
+	// maxlen, and pattern tags, and are used both when rendering (eg to add
+	// a required attribute) and by Builder.Validate. Step is set via the
+	// step tag, and defaults to "any" for float fields.
+	Required  bool
+	Min       string
+	Max       string
+	MinLength int
+	MaxLength int
+	Pattern   string
+	Step      string
+
+	// Options are the choices for select, radio, and similar fields. See
+	// the Optioner interface for more info.
+	Options []Option
+
+	// Repeated is true for fields that are part of a slice or array. When
+	// it is, Index is the index of the element the field belongs to, and
+	// First and Last are true for the first and last elements. These can
+	// be used to render controls for adding and removing rows.
+	Repeated    bool
+	Index       int
+	First, Last bool
+
+	// Groups are the nested structs that this field belongs to, starting
+	// with the outermost struct. This is empty for fields of the value
+	// passed into the Builder. See the Group type for more info.
+	Groups []Group
+}
+
+// Group describes a nested struct field, such as the Address in:
+//
+//   type signupForm struct {
+//     Email   string
+//     Address address `form:"name=addr;legend=Mailing address"`
+//   }
+//
+// Each field of the address would have a Group in its Groups with a Name
+// of "addr" and a Label of "Mailing address". Without the tags, the Go
+// field name is used for both. The label tag can be used in place of the
+// legend tag.
+//
+// Struct elements of a slice or array also have a Group each, named after
+// the element (eg Items.0) and labeled using the tags of the slice field.
+// Struct values of a map do too, but they are labeled using their key,
+// just like other map entries.
+type Group struct {
+	// Name is the prefix used for the names of every field in the group.
+	Name  string
+	Label string
+	// Depth is 1 for structs nested directly in the value passed into the
+	// Builder, 2 for structs nested in those, and so on.
+	Depth int
+}
+
+// group returns groups with a Group for the nested struct field sf, named
+// using path, added to the end. groups is capped first so that sibling
+// structs never share (and overwrite) the same backing array, as fields
+// keep a reference to it.
+func (b *Builder) group(sf reflect.StructField, tags map[string]string, path []string, groups []Group) []Group {
+	g := Group{
+		Name:  b.joinNames(path),
+		Label: b.label(sf.Name),
+		Depth: len(groups) + 1,
+	}
+	if v, ok := tags["label"]; ok {
+		g.Label = b.translate(v)
+	}
+	if v, ok := tags["legend"]; ok {
+		g.Label = b.translate(v)
+	}
+	return append(groups[:len(groups):len(groups)], g)
+}
+
+// isRepeated returns true if values of type t should be expanded into a
+// field for each element. This is true for slices and arrays, except for
+// []byte which is more likely to represent a single value, and is rendered
+// and decoded like a string.
+func isRepeated(t reflect.Type) bool {
+	t = elemType(t)
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return false
+	}
+	return t.Elem().Kind() != reflect.Uint8
+}
+
+// repeatedFields returns the fields for each element in rv, which must be
+// a slice or array (or a pointer to one). Elements are named using their
+// index, so the first element of an Items field would be named Items.0,
+// or Items[0] if the Builder's BracketIndexes is true. Struct elements
+// are expanded like any other nested struct, eg Items.0.Name, and each
+// has its own Group named after the element, eg Items.0.
+//
+// names should include the name of the slice field itself, and tags are
+// used for every element. See containerName for more info.
+func (b *Builder) repeatedFields(sf reflect.StructField, rv reflect.Value, tags map[string]string, names []string, groups []Group, parents []reflect.Type) ([]Field, error) {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+
+	var ret []Field
+	n := rv.Len()
+	for i := 0; i < n; i++ {
+		ev := rv.Index(i)
+		path := append(names, b.indexName(i))
+		var efs []Field
+		if et := elemType(ev.Type()); et.Kind() == reflect.Struct && !b.isLeaf(et) {
+			nested, err := b.walk(ev, path, b.group(sf, tags, path, groups), parents)
+			if err != nil {
+				return nil, err
+			}
+			efs = nested
+		} else {
+			esf := sf
+			esf.Type = ev.Type()
+			f, err := b.field(esf, ev, tags, path)
+			if err != nil {
+				return nil, err
+			}
+			f.Groups = groups
+			efs = []Field{f}
+		}
+		// Nested slices will have already set these, and the innermost
+		// slice is the most useful to templates.
+		for j := range efs {
+			if efs[j].Repeated {
+				continue
+			}
+			efs[j].Repeated = true
+			efs[j].Index = i
+			efs[j].First = i == 0
+			efs[j].Last = i == n-1
+		}
+		ret = append(ret, efs...)
+	}
+	return ret, nil
+}
+
+// isMap returns true if values of type t should be expanded into a field
+// for each key. This is true for maps with string keys.
+func isMap(t reflect.Type) bool {
+	t = elemType(t)
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String
+}
+
+// unsupportedMap returns true if t is a map that can't be expanded into a
+// field for each key, because its keys aren't strings, and that doesn't
+// have a Codec to render it as a single input either, eg map[int]string.
+func (b *Builder) unsupportedMap(t reflect.Type) bool {
+	t = elemType(t)
+	return t.Kind() == reflect.Map && !isMap(t) && b.codec(t, "") == nil
+}
+
+// mapFields returns the fields for each key in rv, which must be a map with
+// string keys (or a pointer to one). Entries are named using their key, so
+// the theme key of a Settings field would be named Settings.theme, and
+// struct values are expanded like any other nested struct. Keys are sorted
+// alphabetically unless the Builder's SortMapKeys is set, and each key is
+// used as its field's label unless the Builder's MapKeyLabel is set.
+//
+// names should include the name of the map field itself, if there is one,
+// and tags are used for every entry. See containerName for more info.
+func (b *Builder) mapFields(sf reflect.StructField, rv reflect.Value, tags map[string]string, names []string, groups []Group, parents []reflect.Type) ([]Field, error) {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+
+	keys := make([]string, 0, rv.Len())
+	for _, k := range rv.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	if b.SortMapKeys != nil {
+		b.SortMapKeys(keys)
+	} else {
+		sort.Strings(keys)
+	}
+
+	var ret []Field
+	for _, key := range keys {
+		ev := rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()))
+		if ev.Kind() == reflect.Interface && !ev.IsNil() {
+			ev = ev.Elem()
+		}
+		path := append(names, key)
+		if et := elemType(ev.Type()); et.Kind() == reflect.Struct && !b.isLeaf(et) {
+			// Each entry is labeled using its key, just like entries with
+			// other values are, unless the map has a label or legend tag.
+			egroups := b.group(sf, tags, path, groups)
+			_, hasLabel := tags["label"]
+			_, hasLegend := tags["legend"]
+			if !hasLabel && !hasLegend {
+				egroups[len(egroups)-1].Label = b.mapKeyLabel(key)
+			}
+			nested, err := b.walk(ev, path, egroups, parents)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, nested...)
+			continue
+		}
+		f, err := b.mapEntryField(sf, ev, tags, path)
+		if err != nil {
+			return nil, err
+		}
+		f.Groups = groups
+		ret = append(ret, f)
+	}
+	return ret, nil
+}
+
+// mapEntryField builds the field for a map entry with a non-struct value.
+// The last name in path is the entry's key.
+func (b *Builder) mapEntryField(sf reflect.StructField, ev reflect.Value, tags map[string]string, path []string) (Field, error) {
+	key := path[len(path)-1]
+	esf := sf
+	esf.Name = key
+	esf.Type = ev.Type()
+	f, err := b.field(esf, ev, tags, path)
+	if err != nil {
+		return Field{}, err
+	}
+	// Keys are often used as is, so they aren't passed through the
+	// LabelFunc.
+	if _, ok := tags["label"]; !ok {
+		f.Label = b.mapKeyLabel(key)
+		if _, ok := tags["placeholder"]; !ok && !b.ExplicitPlaceholders {
+			f.Placeholder = f.Label
+		}
+	}
+	return f, nil
+}
+
+// mapKeyLabel returns the label for a map entry with the provided key.
+func (b *Builder) mapKeyLabel(key string) string {
+	if b.MapKeyLabel != nil {
+		return b.MapKeyLabel(key)
+	}
+	return key
+}
+
+// UnsupportedTypeError is returned when the Builder is asked to render a
+// value whose type can't be represented by HTML inputs, such as a slice
+// passed directly into Inputs or a func field. Path is the name of the
+// field with the unsupported type, as it would have been rendered, and is
+// empty when the value passed into the Builder is itself unsupported.
+type UnsupportedTypeError struct {
+	Path string
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("form: unsupported type %v; only structs and maps with string keys are supported", e.Type)
+	}
+	return fmt.Sprintf("form: unsupported type %v for field %v", e.Type, e.Path)
+}
+
+// InvalidValueError is returned when the Builder is asked to render a value
+// that it can't read, such as a nil interface. Path is the name of the
+// field with the invalid value, and is empty when the value passed into
+// the Builder is itself invalid.
+type InvalidValueError struct {
+	Path   string
+	Reason string
+}
+
+func (e *InvalidValueError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("form: invalid value: %v", e.Reason)
+	}
+	return fmt.Sprintf("form: invalid value for field %v: %v", e.Path, e.Reason)
+}
+
+// hasType returns true if t is in types.
+func hasType(types []reflect.Type, t reflect.Type) bool {
+	for _, tt := range types {
+		if tt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// CycleError is returned when a struct has a nil pointer field whose type
+// is one of the structs it is nested in, such as:
+//
+//   type Category struct {
+//     Name   string
+//     Parent *Category
+//   }
+//
+// Rendering an input for every field of Parent would mean allocating and
+// rendering a new Category forever, so the field should either be ignored
+// with `form:"-"` or the form should use a type without the cycle. Path
+// is the name of the offending field.
+type CycleError struct {
+	Path string
+	Type reflect.Type
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("form: cycle detected at field %v; %v is nested inside of itself", e.Path, e.Type)
+}
+
+// DepthError is returned when structs (or maps) are nested more deeply
+// than the Builder's MaxDepth. Path is the name of the value that was too
+// deep.
+type DepthError struct {
+	Path     string
+	MaxDepth int
+}
+
+func (e *DepthError) Error() string {
+	return fmt.Sprintf("form: field %v exceeds the max depth of %d", e.Path, e.MaxDepth)
 }
@@ -1,8 +1,12 @@
 package form
 
 import (
+	"errors"
+	"fmt"
 	"html/template"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -22,20 +26,21 @@ func Test_fields(t *testing.T) {
 	tests := []struct {
 		name string
 		arg  interface{}
-		want []field
+		want []Field
 	}{
 		{
 			name: "simple and empty",
 			arg: struct {
 				Name string
 			}{},
-			want: []field{
+			want: []Field{
 				{
 					Name:        "Name",
 					Label:       "Name",
 					Placeholder: "Name",
 					Type:        "text",
 					Value:       "",
+					Formatted:   "",
 				},
 			},
 		}, {
@@ -43,13 +48,14 @@ func Test_fields(t *testing.T) {
 			arg: struct {
 				Name string
 			}{"Michael Scott"},
-			want: []field{
+			want: []Field{
 				{
 					Name:        "Name",
 					Label:       "Name",
 					Placeholder: "Name",
 					Type:        "text",
 					Value:       "Michael Scott",
+					Formatted:   "Michael Scott",
 				},
 			},
 		}, {
@@ -58,37 +64,40 @@ func Test_fields(t *testing.T) {
 				Name    string
 				Ignored string `form:"-"`
 			}{"", "secret info"},
-			want: []field{
+			want: []Field{
 				{
 					Name:        "Name",
 					Label:       "Name",
 					Placeholder: "Name",
 					Type:        "text",
 					Value:       "",
+					Formatted:   "",
 				},
 			},
 		}, {
 			name: "pointer to struct w/ val",
 			arg:  &address{},
-			want: []field{
+			want: []Field{
 				{
 					Name:        "Street1",
-					Label:       "Street1",
-					Placeholder: "Street1",
+					Label:       "Street 1",
+					Placeholder: "Street 1",
 					Type:        "text",
 					Value:       "",
+					Formatted:   "",
 				},
 			},
 		}, {
 			name: "nil pointer with type",
 			arg:  nilAddress,
-			want: []field{
+			want: []Field{
 				{
 					Name:        "Street1",
-					Label:       "Street1",
-					Placeholder: "Street1",
+					Label:       "Street 1",
+					Placeholder: "Street 1",
 					Type:        "text",
 					Value:       "",
+					Formatted:   "",
 				},
 			},
 		}, {
@@ -99,19 +108,22 @@ func Test_fields(t *testing.T) {
 					Street1 string
 				}
 			}{},
-			want: []field{
+			want: []Field{
 				{
 					Name:        "Name",
 					Label:       "Name",
 					Placeholder: "Name",
 					Type:        "text",
 					Value:       "",
+					Formatted:   "",
 				}, {
 					Name:        "Address.Street1",
-					Label:       "Street1",
-					Placeholder: "Street1",
+					Label:       "Street 1",
+					Placeholder: "Street 1",
 					Type:        "text",
 					Value:       "",
+					Formatted:   "",
+					Groups:      []Group{{Name: "Address", Label: "Address", Depth: 1}},
 				},
 			},
 		}, {
@@ -123,19 +135,22 @@ func Test_fields(t *testing.T) {
 				Name:    "Michael Scott",
 				Address: address{"123 Test St"},
 			},
-			want: []field{
+			want: []Field{
 				{
 					Name:        "Name",
 					Label:       "Name",
 					Placeholder: "Name",
 					Type:        "text",
 					Value:       "Michael Scott",
+					Formatted:   "Michael Scott",
 				}, {
 					Name:        "Address.Street1",
-					Label:       "Street1",
-					Placeholder: "Street1",
+					Label:       "Street 1",
+					Placeholder: "Street 1",
 					Type:        "text",
 					Value:       "123 Test St",
+					Formatted:   "123 Test St",
+					Groups:      []Group{{Name: "Address", Label: "Address", Depth: 1}},
 				},
 			},
 		}, {
@@ -148,13 +163,14 @@ func Test_fields(t *testing.T) {
 				Name:    "Michael Scott",
 				Address: addressWithTags{"123 Test St"},
 			},
-			want: []field{
+			want: []Field{
 				{
 					Name:        "Name",
 					Label:       "Full Name",
 					Placeholder: "Full Name",
 					Type:        "text",
 					Value:       "Michael Scott",
+					Formatted:   "Michael Scott",
 					ID:          "name",
 				}, {
 					Name:        "Password",
@@ -162,13 +178,45 @@ func Test_fields(t *testing.T) {
 					Placeholder: "Password",
 					Type:        "password",
 					Value:       "",
+					Formatted:   "",
 					Footer:      template.HTML("Something super secret!"),
 				}, {
 					Name:        "street",
-					Label:       "Street1",
-					Placeholder: "Street1",
+					Label:       "Street 1",
+					Placeholder: "Street 1",
 					Type:        "text",
 					Value:       "123 Test St",
+					Formatted:   "123 Test St",
+					Groups:      []Group{{Name: "Address", Label: "Address", Depth: 1}},
+				},
+			},
+		}, {
+			name: "validation tags",
+			arg: struct {
+				Username string `form:"required;minlen=3;maxlen=20;pattern=[a-z]+"`
+				Age      int    `form:"min=13;max=120"`
+			}{},
+			want: []Field{
+				{
+					Name:        "Username",
+					Label:       "Username",
+					Placeholder: "Username",
+					Type:        "text",
+					Value:       "",
+					Formatted:   "",
+					Required:    true,
+					MinLength:   3,
+					MaxLength:   20,
+					Pattern:     "[a-z]+",
+				}, {
+					Name:        "Age",
+					Label:       "Age",
+					Placeholder: "Age",
+					Type:        "number",
+					Value:       0,
+					Formatted:   "0",
+					Min:         "13",
+					Max:         "120",
 				},
 			},
 		}, {
@@ -180,29 +228,437 @@ func Test_fields(t *testing.T) {
 				Name:    "Michael Scott",
 				Address: nil,
 			},
-			want: []field{
+			want: []Field{
 				{
 					Name:        "Name",
 					Label:       "Name",
 					Placeholder: "Name",
 					Type:        "text",
 					Value:       "Michael Scott",
+					Formatted:   "Michael Scott",
 				}, {
 					Name:        "Address.Street1",
-					Label:       "Street1",
-					Placeholder: "Street1",
+					Label:       "Street 1",
+					Placeholder: "Street 1",
 					Type:        "text",
 					Value:       "",
+					Formatted:   "",
+					Groups:      []Group{{Name: "Address", Label: "Address", Depth: 1}},
 				},
 			},
 		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := fields(tc.arg)
+			var b Builder
+			got, err := b.fields(tc.arg)
+			if err != nil {
+				t.Fatalf("fields() err = %v, want %v", err, nil)
+			}
 			if !reflect.DeepEqual(got, tc.want) {
 				t.Errorf("fields(%+v) = %+v, want %+v", tc.arg, got, tc.want)
 			}
 		})
 	}
 }
+
+func Test_parseTags(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  string
+		want map[string]string
+	}{
+		{"empty", "", map[string]string{}},
+		{"key values", "label=Full Name;id=name", map[string]string{
+			"label": "Full Name",
+			"id":    "name",
+		}},
+		{"flags", "required; label=Name;", map[string]string{
+			"required": "",
+			"label":    "Name",
+		}},
+		{"value with equals", "pattern=a=b", map[string]string{
+			"pattern": "a=b",
+		}},
+		{"ignored", "label=Name;-", map[string]string{
+			"-": "this field is ignored",
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseTags(tc.arg)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseTags(%q) = %v, want %v", tc.arg, got, tc.want)
+			}
+		})
+	}
+}
+
+func Test_fields_repeated(t *testing.T) {
+	type item struct {
+		Name string
+		Qty  int
+	}
+	type order struct {
+		Tags   []string `form:"name=tag;label=Tag"`
+		Phones [2]string
+		Items  []item
+		Notes  *[]string
+		Data   []byte
+	}
+	arg := order{
+		Tags:   []string{"a", "b", "c"},
+		Phones: [2]string{"555-1234"},
+		Items:  []item{{"Widget", 2}},
+		Data:   []byte("hi"),
+	}
+	type meta struct {
+		Name, Label, Formatted string
+		Repeated, First, Last  bool
+		Index                  int
+	}
+	tests := []struct {
+		name     string
+		brackets bool
+		want     []meta
+	}{
+		{
+			name: "dots",
+			want: []meta{
+				{"tag.0", "Tag", "a", true, true, false, 0},
+				{"tag.1", "Tag", "b", true, false, false, 1},
+				{"tag.2", "Tag", "c", true, false, true, 2},
+				{"Phones.0", "Phones", "555-1234", true, true, false, 0},
+				{"Phones.1", "Phones", "", true, false, true, 1},
+				{"Items.0.Name", "Name", "Widget", true, true, true, 0},
+				{"Items.0.Qty", "Qty", "2", true, true, true, 0},
+				{"Data", "Data", "hi", false, false, false, 0},
+			},
+		}, {
+			name:     "brackets",
+			brackets: true,
+			want: []meta{
+				{"tag[0]", "Tag", "a", true, true, false, 0},
+				{"tag[1]", "Tag", "b", true, false, false, 1},
+				{"tag[2]", "Tag", "c", true, false, true, 2},
+				{"Phones[0]", "Phones", "555-1234", true, true, false, 0},
+				{"Phones[1]", "Phones", "", true, false, true, 1},
+				{"Items[0].Name", "Name", "Widget", true, true, true, 0},
+				{"Items[0].Qty", "Qty", "2", true, true, true, 0},
+				{"Data", "Data", "hi", false, false, false, 0},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &Builder{BracketIndexes: tc.brackets}
+			var got []meta
+			fields, err := b.fields(arg)
+			if err != nil {
+				t.Fatalf("fields() err = %v, want %v", err, nil)
+			}
+			for _, f := range fields {
+				got = append(got, meta{f.Name, f.Label, f.Formatted, f.Repeated, f.First, f.Last, f.Index})
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("fields() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func Test_fields_maps(t *testing.T) {
+	type address struct {
+		Street1 string
+	}
+	type settings struct {
+		Prefs     map[string]string `form:"name=pref"`
+		Addresses map[string]address
+		Missing   map[string]string
+	}
+	arg := settings{
+		Prefs: map[string]string{"theme": "dark", "email": "a@b.co", "lang": "en"},
+		Addresses: map[string]address{
+			"office": {"1725 Slough Ave"},
+			"home":   {"123 Test St"},
+		},
+	}
+	type meta struct {
+		Name, Label, Type, Formatted string
+	}
+	tests := []struct {
+		name string
+		b    *Builder
+		arg  interface{}
+		want []meta
+	}{
+		{
+			name: "nested",
+			b:    &Builder{},
+			arg:  arg,
+			want: []meta{
+				{"pref.email", "email", "email", "a@b.co"},
+				{"pref.lang", "lang", "text", "en"},
+				{"pref.theme", "theme", "text", "dark"},
+				{"Addresses.home.Street1", "Street 1", "text", "123 Test St"},
+				{"Addresses.office.Street1", "Street 1", "text", "1725 Slough Ave"},
+			},
+		}, {
+			name: "top level",
+			b:    &Builder{},
+			arg:  map[string]interface{}{"b": 2, "a": "one"},
+			want: []meta{
+				{"a", "a", "text", "one"},
+				{"b", "b", "number", "2"},
+			},
+		}, {
+			name: "custom order and labels",
+			b: &Builder{
+				SortMapKeys: func(keys []string) {
+					sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+				},
+				MapKeyLabel: strings.ToUpper,
+			},
+			arg: arg.Prefs,
+			want: []meta{
+				{"theme", "THEME", "text", "dark"},
+				{"lang", "LANG", "text", "en"},
+				{"email", "EMAIL", "email", "a@b.co"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []meta
+			fields, err := tc.b.fields(tc.arg)
+			if err != nil {
+				t.Fatalf("fields() err = %v, want %v", err, nil)
+			}
+			for _, f := range fields {
+				got = append(got, meta{f.Name, f.Label, f.Type, f.Formatted})
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("fields() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func Test_fields_errors(t *testing.T) {
+	var nilIface interface{}
+	tests := []struct {
+		name    string
+		arg     interface{}
+		wantErr interface{}
+		path    string
+	}{
+		{"nil", nil, &InvalidValueError{}, ""},
+		{"nil interface pointer", &nilIface, &InvalidValueError{}, ""},
+		{"slice", []string{"a"}, &UnsupportedTypeError{}, ""},
+		{"string", "hi", &UnsupportedTypeError{}, ""},
+		{"func field", struct{ OnSave func() }{}, &UnsupportedTypeError{}, "OnSave"},
+		{"chan field", struct {
+			Nested struct{ Events chan int }
+		}{}, &UnsupportedTypeError{}, "Nested.Events"},
+		{"func element", struct{ Hooks []func() }{Hooks: []func(){nil}}, &UnsupportedTypeError{}, "Hooks.0"},
+		{"int keyed map", map[int]string{1: "a"}, &UnsupportedTypeError{}, ""},
+		{"int keyed map field", struct{ Lookup map[int]string }{Lookup: map[int]string{1: "a"}}, &UnsupportedTypeError{}, "Lookup"},
+		{"int keyed map value", struct {
+			Lookups map[string]map[int]string
+		}{Lookups: map[string]map[int]string{"a": nil}}, &UnsupportedTypeError{}, "Lookups.a"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b Builder
+			_, err := b.fields(tc.arg)
+			var path string
+			switch tc.wantErr.(type) {
+			case *InvalidValueError:
+				var ive *InvalidValueError
+				if !errors.As(err, &ive) {
+					t.Fatalf("fields() err = %v, want an *InvalidValueError", err)
+				}
+				path = ive.Path
+			case *UnsupportedTypeError:
+				var ute *UnsupportedTypeError
+				if !errors.As(err, &ute) {
+					t.Fatalf("fields() err = %v, want an *UnsupportedTypeError", err)
+				}
+				path = ute.Path
+			}
+			if path != tc.path {
+				t.Errorf("fields() err Path = %q, want %q", path, tc.path)
+			}
+		})
+	}
+}
+
+func Test_fields_nestedTags(t *testing.T) {
+	type address struct {
+		Street1 string
+		Zip     string `form:"label=ZIP"`
+	}
+	type inner struct {
+		Address address `form:"legend=Home"`
+	}
+	arg := struct {
+		Billing  address  `form:"name=billing;label=Billing address"`
+		Shipping *address `form:"name=shipping;legend=Shipping address"`
+		Internal address  `form:"-"`
+		Profile  inner
+	}{
+		Billing: address{Street1: "123 Test St"},
+	}
+	billing := []Group{{Name: "billing", Label: "Billing address", Depth: 1}}
+	shipping := []Group{{Name: "shipping", Label: "Shipping address", Depth: 1}}
+	profile := []Group{
+		{Name: "Profile", Label: "Profile", Depth: 1},
+		{Name: "Profile.Address", Label: "Home", Depth: 2},
+	}
+	type meta struct {
+		Name, Label, Formatted string
+		Groups                 []Group
+	}
+	want := []meta{
+		{"billing.Street1", "Street 1", "123 Test St", billing},
+		{"billing.Zip", "ZIP", "", billing},
+		{"shipping.Street1", "Street 1", "", shipping},
+		{"shipping.Zip", "ZIP", "", shipping},
+		{"Profile.Address.Street1", "Street 1", "", profile},
+		{"Profile.Address.Zip", "ZIP", "", profile},
+	}
+	var b Builder
+	fields, err := b.fields(arg)
+	if err != nil {
+		t.Fatalf("fields() err = %v, want %v", err, nil)
+	}
+	var got []meta
+	for _, f := range fields {
+		got = append(got, meta{f.Name, f.Label, f.Formatted, f.Groups})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields() = %+v, want %+v", got, want)
+	}
+}
+
+type testCategory struct {
+	Name   string
+	Parent *testCategory
+}
+
+type testNode struct {
+	Name     string
+	Children []testNode
+}
+
+func Test_fields_cycles(t *testing.T) {
+	var b Builder
+	_, err := b.fields(testCategory{Name: "Shoes"})
+	var ce *CycleError
+	if !errors.As(err, &ce) {
+		t.Fatalf("fields() err = %v, want a *CycleError", err)
+	}
+	if ce.Path != "Parent" {
+		t.Errorf("CycleError.Path = %q, want %q", ce.Path, "Parent")
+	}
+
+	_, err = b.fields(struct {
+		Name   string
+		Parent *testCategory `form:"-"`
+	}{})
+	if err != nil {
+		t.Errorf("fields() err = %v, want %v", err, nil)
+	}
+
+	// Recursive types are fine as long as the values end.
+	fields, err := b.fields(testNode{
+		Name:     "root",
+		Children: []testNode{{Name: "a"}, {Name: "b", Children: []testNode{{Name: "c"}}}},
+	})
+	if err != nil {
+		t.Fatalf("fields() err = %v, want %v", err, nil)
+	}
+	var got []string
+	for _, f := range fields {
+		got = append(got, f.Name)
+	}
+	want := []string{"Name", "Children.0.Name", "Children.1.Name", "Children.1.Children.0.Name"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields() names = %v, want %v", got, want)
+	}
+}
+
+func Test_fields_maxDepth(t *testing.T) {
+	type c struct{ Name string }
+	type b struct{ C c }
+	type a struct{ B b }
+	arg := struct{ A a }{}
+	tests := []struct {
+		maxDepth int
+		wantErr  bool
+	}{
+		{0, false},
+		{4, false},
+		{3, true},
+		{1, true},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprint(tc.maxDepth), func(t *testing.T) {
+			b := &Builder{MaxDepth: tc.maxDepth}
+			_, err := b.fields(arg)
+			var de *DepthError
+			if got := errors.As(err, &de); got != tc.wantErr {
+				t.Errorf("fields() err = %v, want a *DepthError = %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func Test_fields_pointers(t *testing.T) {
+	type address struct {
+		Street1 string
+	}
+	name, zero, rating := "Michael", 0, 4.5
+	pname := &name
+	arg := struct {
+		Name     *string
+		Nickname *string
+		Age      *int
+		Count    *int
+		Rating   *float64
+		PName    **string
+		Address  *address
+	}{
+		Name:    &name,
+		Count:   &zero,
+		Rating:  &rating,
+		PName:   &pname,
+		Address: &address{Street1: "123 Test St"},
+	}
+	type meta struct {
+		Name      string
+		Value     interface{}
+		Formatted string
+	}
+	want := []meta{
+		{"Name", "Michael", "Michael"},
+		{"Nickname", nil, ""},
+		{"Age", nil, ""},
+		{"Count", 0, "0"},
+		{"Rating", 4.5, "4.5"},
+		{"PName", "Michael", "Michael"},
+		{"Address.Street1", "123 Test St", "123 Test St"},
+	}
+	var b Builder
+	fields, err := b.fields(arg)
+	if err != nil {
+		t.Fatalf("fields() err = %v, want %v", err, nil)
+	}
+	var got []meta
+	for _, f := range fields {
+		got = append(got, meta{f.Name, f.Value, f.Formatted})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,56 @@
+package form
+
+import "github.com/gorilla/schema"
+
+// FromSchema converts an error returned by gorilla/schema's Decoder into
+// an Errors value, with a field error in place of each error that belongs
+// to a field. This makes it possible to render a form again, along with
+// the user's input, when it fails to decode. Eg:
+//
+//   var signup signupForm
+//   if err := dec.Decode(&signup, r.PostForm); err != nil {
+//     errs := form.FromSchema(err)
+//     // render the form with signup and errs
+//   }
+//
+// The schema errors are converted using their Key, which is the same as
+// the name of the input when using the default Naming:
+//
+//   schema.ConversionError - a *DecodeError for the field
+//   schema.EmptyFieldError - a *ValidationError with the required rule
+//   schema.UnknownKeyError - a *FieldError with the unknown code
+//
+// Other errors are kept as they are, and are treated as form-level errors
+// when rendered. It isn't necessary to call FromSchema before passing an
+// error to Inputs, as it converts schema errors the same way, but the
+// returned Errors can be inspected and added to before rendering.
+func FromSchema(err error) Errors {
+	if err == nil {
+		return nil
+	}
+	return Errors(flattenErrors([]error{err}))
+}
+
+// schemaFieldError returns the field error for err if it is one of the
+// errors gorilla/schema returns for a specific key. See FromSchema.
+func schemaFieldError(err error) (error, bool) {
+	switch e := err.(type) {
+	case *schema.ConversionError:
+		return schemaFieldError(*e)
+	case *schema.EmptyFieldError:
+		return schemaFieldError(*e)
+	case *schema.UnknownKeyError:
+		return schemaFieldError(*e)
+	case schema.ConversionError:
+		de := &DecodeError{Field: e.Key, Err: e}
+		if e.Type != nil {
+			de.Kind = elemType(e.Type).Kind()
+		}
+		return de, true
+	case schema.EmptyFieldError:
+		return &ValidationError{Field: e.Key, Rule: "required", Message: "is required"}, true
+	case schema.UnknownKeyError:
+		return &FieldError{Field: e.Key, Message: "is not a valid field", Code: "unknown"}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,115 @@
+package form
+
+import (
+	"errors"
+	"html/template"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/schema"
+)
+
+func TestFromSchema(t *testing.T) {
+	type signup struct {
+		Name string `schema:"Name,required"`
+		Age  int
+		Tags []int
+	}
+	var dst signup
+	err := schema.NewDecoder().Decode(&dst, url.Values{
+		"Name":  {""},
+		"Age":   {"abc"},
+		"Tags":  {"1", "two"},
+		"Other": {"x"},
+	})
+	if err == nil {
+		t.Fatalf("Decode() err = nil, want an error")
+	}
+
+	errs := FromSchema(err)
+	got := make(map[string][]string)
+	for _, field := range []string{"Name", "Age", "Tags", "Other"} {
+		got[field] = errs.For(field)
+	}
+	want := map[string][]string{
+		"Name":  {"is required"},
+		"Age":   {"must be a whole number"},
+		"Tags":  {"must be a whole number"},
+		"Other": {"is not a valid field"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromSchema().For() = %v, want %v", got, want)
+	}
+	var ce schema.ConversionError
+	if !errors.As(errs, &ce) || ce.Key != "Age" {
+		t.Errorf("errors.As(FromSchema(), ConversionError) = %v, want the Age error", ce)
+	}
+	if FromSchema(nil) != nil {
+		t.Errorf("FromSchema(nil) != nil")
+	}
+}
+
+func Test_schemaFieldError(t *testing.T) {
+	other := errors.New("other")
+	tests := []struct {
+		name string
+		arg  error
+		want error
+	}{
+		{
+			name: "conversion",
+			arg:  schema.ConversionError{Key: "Age", Type: reflect.TypeOf(new(int)), Index: -1},
+			want: &DecodeError{Field: "Age", Kind: reflect.Int, Err: schema.ConversionError{Key: "Age", Type: reflect.TypeOf(new(int)), Index: -1}},
+		}, {
+			name: "conversion pointer",
+			arg:  &schema.ConversionError{Key: "Price", Index: -1},
+			want: &DecodeError{Field: "Price", Err: schema.ConversionError{Key: "Price", Index: -1}},
+		}, {
+			name: "empty",
+			arg:  schema.EmptyFieldError{Key: "Name"},
+			want: &ValidationError{Field: "Name", Rule: "required", Message: "is required"},
+		}, {
+			name: "unknown",
+			arg:  &schema.UnknownKeyError{Key: "Admin"},
+			want: &FieldError{Field: "Admin", Message: "is not a valid field", Code: "unknown"},
+		}, {
+			name: "other",
+			arg:  other,
+			want: nil,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := schemaFieldError(tc.arg)
+			if ok != (tc.want != nil) {
+				t.Fatalf("schemaFieldError() ok = %v, want %v", ok, tc.want != nil)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("schemaFieldError() = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_Inputs_schemaErrors(t *testing.T) {
+	type signup struct {
+		Name string
+		Age  int
+	}
+	var dst signup
+	err := schema.NewDecoder().Decode(&dst, url.Values{
+		"Name": {"Michael"},
+		"Age":  {"old"},
+	})
+	tpl := template.Must(template.New("").Funcs(FuncMap()).Parse(`<input name="{{.Name}}" value="{{.Formatted}}">{{range errors}}<p>{{.}}</p>{{end}}`))
+	b := &Builder{InputTemplate: tpl}
+	got, rerr := b.Inputs(dst, err)
+	if rerr != nil {
+		t.Fatalf("Builder.Inputs() err = %v, want %v", rerr, nil)
+	}
+	want := template.HTML(`<input name="Name" value="Michael"><input name="Age" value="0"><p>must be a whole number</p>`)
+	if got != want {
+		t.Errorf("Builder.Inputs() = %v, want %v", got, want)
+	}
+}
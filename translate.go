@@ -0,0 +1,105 @@
+package form
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// Translator is used to translate message keys into text for a locale,
+// such as "en" or "fr-CA". Tags reference message keys by prefixing them
+// with an @, eg:
+//
+//   type signupForm struct {
+//     Email string `form:"label=@user.email;placeholder=@user.email.hint"`
+//   }
+//
+// The label, placeholder, footer, and legend tags all support message
+// keys, as do option labels and the messages of field errors. args are
+// any values the message should include, and are empty for messages
+// that come from tags. Translations are always treated as text, so a
+// translated footer is escaped rather than rendered as HTML.
+type Translator interface {
+	Translate(locale, key string, args ...interface{}) string
+}
+
+// MapTranslator is a Translator backed by a map of locales to the
+// messages for each locale. It is mostly useful in tests and for small
+// sites, eg:
+//
+//   form.MapTranslator{
+//     "en": {"user.email": "Email address"},
+//     "fr": {"user.email": "Adresse e-mail"},
+//   }
+//
+// If a message isn't found for a locale with a region, like "fr-CA", the
+// base language ("fr") is tried next. Keys that can't be found at all are
+// returned as-is. Messages are formatted with fmt.Sprintf when there are
+// args.
+type MapTranslator map[string]map[string]string
+
+// Translate returns the message for key in the given locale.
+func (mt MapTranslator) Translate(locale, key string, args ...interface{}) string {
+	for {
+		if msg, ok := mt[locale][key]; ok {
+			if len(args) > 0 {
+				return fmt.Sprintf(msg, args...)
+			}
+			return msg
+		}
+		i := strings.LastIndexAny(locale, "-_")
+		if i < 0 {
+			return key
+		}
+		locale = locale[:i]
+	}
+}
+
+// InputsLocale is the same as Inputs, but translates message keys into
+// the provided locale rather than the Builder's Locale. This makes it
+// possible to share one Builder between requests for different languages.
+func (b *Builder) InputsLocale(locale string, v interface{}, errs ...error) (template.HTML, error) {
+	return b.InputsContext(WithLocale(context.Background(), locale), v, errs...)
+}
+
+// translate returns s translated with the Builder's Translator if it is a
+// message key, eg @user.email. Other strings are returned as-is, as is
+// everything when there isn't a Translator. A leading @@ can be used for
+// text that should start with a literal @.
+func (b *Builder) translate(s string) string {
+	switch {
+	case strings.HasPrefix(s, "@@"):
+		return s[1:]
+	case strings.HasPrefix(s, "@") && b.Translator != nil:
+		if ct, ok := b.Translator.(ContextTranslator); ok {
+			return ct.TranslateContext(b.context(), b.Locale, s[1:])
+		}
+		return b.Translator.Translate(b.Locale, s[1:])
+	default:
+		return s
+	}
+}
+
+// translateTags returns a copy of tags with the values of any tags that
+// support message keys translated.
+func (b *Builder) translateTags(tags map[string]string) map[string]string {
+	ret := make(map[string]string, len(tags))
+	for k, v := range tags {
+		switch k {
+		case "label", "placeholder", "legend":
+			v = b.translate(v)
+		case "footer":
+			// Footers are rendered as HTML, but translations may not be
+			// trusted, eg if they are a user's own wording, so they are
+			// escaped.
+			if strings.HasPrefix(v, "@") && !strings.HasPrefix(v, "@@") {
+				v = template.HTMLEscapeString(b.translate(v))
+			} else {
+				v = b.translate(v)
+			}
+		}
+		ret[k] = v
+	}
+	return ret
+}
@@ -0,0 +1,121 @@
+package form
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestMapTranslator_Translate(t *testing.T) {
+	mt := MapTranslator{
+		"en":    {"greeting": "Hello", "count": "%d items"},
+		"en-GB": {"greeting": "Hiya"},
+	}
+	tests := []struct {
+		locale, key string
+		args        []interface{}
+		want        string
+	}{
+		{"en", "greeting", nil, "Hello"},
+		{"en-GB", "greeting", nil, "Hiya"},
+		{"en-US", "greeting", nil, "Hello"},
+		{"en_AU", "greeting", nil, "Hello"},
+		{"en", "count", []interface{}{3}, "3 items"},
+		{"fr", "greeting", nil, "greeting"},
+		{"en", "missing", nil, "missing"},
+	}
+	for _, tc := range tests {
+		if got := mt.Translate(tc.locale, tc.key, tc.args...); got != tc.want {
+			t.Errorf("Translate(%q, %q) = %q, want %q", tc.locale, tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestBuilder_InputsLocale(t *testing.T) {
+	tpl := template.Must(template.New("").Funcs(FuncMap()).Parse(strings.TrimSpace(`
+		<label>{{.Label}}</label><input name="{{.Name}}" placeholder="{{.Placeholder}}">{{range .Options}}<option>{{.Label}}</option>{{end}}{{range errors}}<p>{{.}}</p>{{end}}{{.Footer}}
+	`)))
+	gtpl := template.Must(template.New("").Parse(`<fieldset><legend>{{.Label}}</legend>{{.Children}}</fieldset>`))
+	type address struct {
+		City string `form:"label=@address.city"`
+	}
+	arg := struct {
+		Email   string  `form:"label=@user.email;placeholder=@user.email.hint;footer=@user.email.footer"`
+		Handle  string  `form:"label=@@handle"`
+		Status  string  `form:"options=draft:@status.draft,live"`
+		Address address `form:"legend=@address"`
+	}{}
+	b := &Builder{
+		InputTemplate: tpl,
+		GroupTemplate: gtpl,
+		Locale:        "en",
+		Translator: MapTranslator{
+			"en": {
+				"user.email":        "Email",
+				"user.email.hint":   "you@example.com",
+				"user.email.footer": "We <b>never</b> share it.",
+				"status.draft":      "Draft",
+				"address":           "Address",
+				"address.city":      "City",
+				"errors.taken":      "is taken",
+			},
+			"fr": {
+				"user.email":   "Adresse e-mail",
+				"status.draft": "Brouillon",
+				"address":      "Adresse",
+				"address.city": "Ville",
+				"errors.taken": "est déjà pris",
+			},
+		},
+	}
+	errs := []error{testFieldError{"Email", "@errors.taken"}, testFieldError{"Handle", "is short"}}
+	tests := []struct {
+		name   string
+		locale string
+		want   string
+	}{
+		{
+			name:   "en",
+			locale: "en",
+			want: `<label>Email</label><input name="Email" placeholder="you@example.com"><p>is taken</p>We &lt;b&gt;never&lt;/b&gt; share it.` +
+				`<label>@handle</label><input name="Handle" placeholder="@handle"><p>is short</p>` +
+				`<label>Status</label><input name="Status" placeholder="Status"><option>Draft</option><option>live</option>` +
+				`<fieldset><legend>Address</legend><label>City</label><input name="Address.City" placeholder="City"></fieldset>`,
+		}, {
+			name:   "fr with missing keys",
+			locale: "fr",
+			want: `<label>Adresse e-mail</label><input name="Email" placeholder="user.email.hint"><p>est déjà pris</p>user.email.footer` +
+				`<label>@handle</label><input name="Handle" placeholder="@handle"><p>is short</p>` +
+				`<label>Status</label><input name="Status" placeholder="Status"><option>Brouillon</option><option>live</option>` +
+				`<fieldset><legend>Adresse</legend><label>Ville</label><input name="Address.City" placeholder="Ville"></fieldset>`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := b.InputsLocale(tc.locale, arg, errs...)
+			if err != nil {
+				t.Fatalf("Builder.InputsLocale() err = %v, want %v", err, nil)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Builder.InputsLocale() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+	if b.Locale != "en" {
+		t.Errorf("Builder.Locale = %q after InputsLocale, want %q", b.Locale, "en")
+	}
+}
+
+func TestBuilder_Inputs_noTranslator(t *testing.T) {
+	tpl := template.Must(template.New("").Parse(`<label>{{.Label}}</label>`))
+	b := &Builder{InputTemplate: tpl}
+	got, err := b.Inputs(struct {
+		Email string `form:"label=@user.email"`
+	}{})
+	if err != nil {
+		t.Fatalf("Builder.Inputs() err = %v, want %v", err, nil)
+	}
+	if want := template.HTML(`<label>@user.email</label>`); got != want {
+		t.Errorf("Builder.Inputs() = %v, want %v", got, want)
+	}
+}
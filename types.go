@@ -0,0 +1,166 @@
+package form
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TypeMapper is used to determine the default input type for a struct
+// field when one isn't provided via a type tag. An empty string means the
+// TypeMapper doesn't have an opinion, in which case "text" is used.
+//
+// The Builder uses DefaultTypeMapper unless its TypeMapper field is set.
+// Custom rules can be combined with the defaults via TypeMappers, eg:
+//
+//   fb := form.Builder{
+//     TypeMapper: form.TypeMappers{
+//       form.NameTypes{"secret": "password"},
+//       form.DefaultTypeMapper,
+//     },
+//   }
+type TypeMapper interface {
+	InputType(sf reflect.StructField) string
+}
+
+// TypeMapperFunc is a function that implements the TypeMapper interface.
+type TypeMapperFunc func(sf reflect.StructField) string
+
+// InputType calls fn(sf).
+func (fn TypeMapperFunc) InputType(sf reflect.StructField) string {
+	return fn(sf)
+}
+
+// TypeMappers is a list of TypeMappers that are tried in order. The first
+// non-empty input type is used.
+type TypeMappers []TypeMapper
+
+// InputType returns the first non-empty input type from the TypeMappers.
+func (tms TypeMappers) InputType(sf reflect.StructField) string {
+	for _, tm := range tms {
+		if t := tm.InputType(sf); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+// KindTypes is a TypeMapper that maps a field's reflect.Kind to an input
+// type. Pointers and sql.Null* types are unwrapped first, so *int,
+// sql.NullInt64, and int are all treated the same.
+type KindTypes map[reflect.Kind]string
+
+// InputType returns the input type for the kind of the field.
+func (kt KindTypes) InputType(sf reflect.StructField) string {
+	return kt[valueType(sf.Type).Kind()]
+}
+
+// NameTypes is a TypeMapper that maps string fields to an input type based
+// on their name. Each key is compared against the lowercased field name and
+// matches if the name contains it, so "email" would match both Email and
+// WorkEmail. When multiple keys match, the longest is used.
+type NameTypes map[string]string
+
+// InputType returns the input type for the longest key contained in the
+// field's name, if the field is a string.
+func (nt NameTypes) InputType(sf reflect.StructField) string {
+	if valueType(sf.Type).Kind() != reflect.String {
+		return ""
+	}
+	name := strings.ToLower(sf.Name)
+	keys := make([]string, 0, len(nt))
+	for k := range nt {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		if strings.Contains(name, k) {
+			return nt[k]
+		}
+	}
+	return ""
+}
+
+var (
+	// DefaultKindTypes maps numeric kinds to the number type and bools to
+	// the checkbox type.
+	DefaultKindTypes = KindTypes{
+		reflect.Int:     "number",
+		reflect.Int8:    "number",
+		reflect.Int16:   "number",
+		reflect.Int32:   "number",
+		reflect.Int64:   "number",
+		reflect.Uint:    "number",
+		reflect.Uint8:   "number",
+		reflect.Uint16:  "number",
+		reflect.Uint32:  "number",
+		reflect.Uint64:  "number",
+		reflect.Float32: "number",
+		reflect.Float64: "number",
+		reflect.Bool:    "checkbox",
+	}
+
+	// DefaultNameTypes provides email, url, and tel types for string fields
+	// with names that suggest them.
+	DefaultNameTypes = NameTypes{
+		"email":   "email",
+		"url":     "url",
+		"website": "url",
+		"phone":   "tel",
+	}
+
+	// DefaultTypeMapper is the TypeMapper used by a Builder when one isn't
+	// provided. time.Time fields use the datetime-local type, then
+	// DefaultNameTypes and DefaultKindTypes are checked in that order.
+	DefaultTypeMapper TypeMapper = TypeMappers{
+		TypeMapperFunc(timeType),
+		DefaultNameTypes,
+		DefaultKindTypes,
+	}
+)
+
+var timeReflectType = reflect.TypeOf(time.Time{})
+
+func timeType(sf reflect.StructField) string {
+	if valueType(sf.Type) == timeReflectType {
+		return "datetime-local"
+	}
+	return ""
+}
+
+// elemType dereferences pointer types until it reaches a non-pointer type.
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// valueType is like elemType, but it also unwraps sql.Null* types so that
+// a sql.NullInt64 is treated like an int64 when determining input types.
+func valueType(t reflect.Type) reflect.Type {
+	t = elemType(t)
+	if isSQLNull(t) {
+		return elemType(t.Field(0).Type)
+	}
+	return t
+}
+
+// inputType returns the default input type for a field using the Builder's
+// TypeMapper, falling back to "text".
+func (b *Builder) inputType(sf reflect.StructField) string {
+	tm := b.TypeMapper
+	if tm == nil {
+		tm = DefaultTypeMapper
+	}
+	if t := tm.InputType(sf); t != "" {
+		return t
+	}
+	return "text"
+}
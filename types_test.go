@@ -0,0 +1,126 @@
+package form
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuilder_inputType(t *testing.T) {
+	type example struct {
+		Name        string
+		Age         int
+		Count       *uint8
+		Price       float64
+		Admin       bool
+		WorkEmail   string
+		WebsiteURL  string
+		PhoneNumber *string
+		PhoneCount  int
+		CreatedAt   time.Time
+		UpdatedAt   *time.Time
+	}
+	tests := []struct {
+		name   string
+		mapper TypeMapper
+		want   map[string]string
+	}{
+		{
+			name:   "default",
+			mapper: nil,
+			want: map[string]string{
+				"Name":        "text",
+				"Age":         "number",
+				"Count":       "number",
+				"Price":       "number",
+				"Admin":       "checkbox",
+				"WorkEmail":   "email",
+				"WebsiteURL":  "url",
+				"PhoneNumber": "tel",
+				"PhoneCount":  "number",
+				"CreatedAt":   "datetime-local",
+				"UpdatedAt":   "datetime-local",
+			},
+		}, {
+			name:   "kinds only",
+			mapper: DefaultKindTypes,
+			want: map[string]string{
+				"Name":        "text",
+				"Age":         "number",
+				"Admin":       "checkbox",
+				"WorkEmail":   "text",
+				"PhoneNumber": "text",
+				"CreatedAt":   "text",
+			},
+		}, {
+			name: "custom first",
+			mapper: TypeMappers{
+				TypeMapperFunc(func(sf reflect.StructField) string {
+					if sf.Name == "Age" {
+						return "range"
+					}
+					return ""
+				}),
+				NameTypes{"name": "search"},
+				DefaultTypeMapper,
+			},
+			want: map[string]string{
+				"Name":      "search",
+				"Age":       "range",
+				"Price":     "number",
+				"WorkEmail": "email",
+			},
+		},
+	}
+	typ := reflect.TypeOf(example{})
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &Builder{TypeMapper: tc.mapper}
+			for name, want := range tc.want {
+				sf, _ := typ.FieldByName(name)
+				if got := b.inputType(sf); got != want {
+					t.Errorf("Builder.inputType(%v) = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNameTypes_longestMatch(t *testing.T) {
+	nt := NameTypes{"email": "email", "emailconfirm": "text"}
+	sf := reflect.StructField{Name: "EmailConfirmation", Type: reflect.TypeOf("")}
+	if got := nt.InputType(sf); got != "text" {
+		t.Errorf("NameTypes.InputType() = %v, want %v", got, "text")
+	}
+}
+
+func Test_fields_inferredTypes(t *testing.T) {
+	var b Builder
+	got, err := b.fields(struct {
+		Price    float64
+		Quantity int     `form:"step=5"`
+		Weight   float32 `form:"type=text"`
+		Email    string
+		Secret   string `form:"type=password"`
+	}{})
+	if err != nil {
+		t.Fatalf("fields() err = %v, want %v", err, nil)
+	}
+	want := []struct {
+		Type, Step string
+	}{
+		{"number", "any"},
+		{"number", "5"},
+		{"text", ""},
+		{"email", ""},
+		{"password", ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("len(fields()) = %d, want %d", len(got), len(want))
+	}
+	for i, f := range got {
+		if f.Type != want[i].Type || f.Step != want[i].Step {
+			t.Errorf("fields()[%d] type = %q step = %q, want type = %q step = %q", i, f.Type, f.Step, want[i].Type, want[i].Step)
+		}
+	}
+}
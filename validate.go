@@ -0,0 +1,190 @@
+package form
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Validate checks each field in v against the validation rules provided
+// via its struct tags. These are the same rules that are made available
+// to the InputTemplate, so the HTML constraints and the server side
+// checks can't drift apart. The supported tags are:
+//
+//   required       - the field must not be empty, eg "" or a nil pointer,
+//                    and checkboxes must be checked
+//   min=3          - numeric fields must be >= 3
+//   max=64         - numeric fields must be <= 64
+//   minlen=2       - string fields must have at least 2 characters
+//   maxlen=64      - string fields must have at most 64 characters
+//   pattern=[a-z]+ - string fields must entirely match the regexp
+//
+// Eg:
+//
+//   type signupForm struct {
+//     Username string `form:"required;minlen=3;maxlen=20;pattern=[a-z0-9]+"`
+//     Age      int    `form:"min=13"`
+//   }
+//
+// Empty values, like an empty string or a nil pointer, are only checked by
+// the required rule, just like they are in the browser. Numbers are always
+// rendered with a value though, so min and max still apply to a 0. If any rules fail, an Errors value is returned with a
+// *ValidationError for each failure. These implement the FieldErrorer
+// interface, so they can be passed directly into inputs_and_errors_for.
+//
+// An error that isn't part of an Errors value is returned if a rule itself
+// is invalid, such as a pattern that doesn't compile, or if v can't be
+// walked in the first place.
+//
+// Fields left out by the Builder's FieldFilter aren't validated. Use
+// ValidateContext to provide the filter with a context.
+func (b *Builder) Validate(v interface{}) error {
+	fields, err := b.filteredFields(v)
+	if err != nil {
+		return err
+	}
+	var errs Errors
+	for _, f := range fields {
+		ferrs, err := validateField(f)
+		if err != nil {
+			return err
+		}
+		errs = append(errs, ferrs...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateField checks a single field's value against its rules.
+func validateField(f Field) (Errors, error) {
+	var errs Errors
+	rv := reflect.ValueOf(f.Value)
+	for rv.IsValid() && (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) {
+		rv = rv.Elem()
+	}
+	// Only values rendered as an empty input are treated as missing, just
+	// like they are in the browser. A 0 is rendered as "0", so it is still
+	// checked against min and max, and a *int set to 0 is a value while a
+	// nil one isn't.
+	empty := !rv.IsValid() || f.Formatted == ""
+	if f.Required && (empty || f.Type == "checkbox" && f.Formatted == "false") {
+		errs.add(&ValidationError{Field: f.Name, Rule: "required", Message: "is required"})
+		return errs, nil
+	}
+	if empty {
+		return errs, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return validateNumber(f, float64(rv.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return validateNumber(f, float64(rv.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return validateNumber(f, rv.Float())
+	case reflect.String:
+		s := rv.String()
+		n := utf8.RuneCountInString(s)
+		if f.MinLength > 0 && n < f.MinLength {
+			errs.add(&ValidationError{
+				Field:   f.Name,
+				Rule:    "minlen",
+				Message: fmt.Sprintf("must be at least %d characters", f.MinLength),
+				Params:  map[string]interface{}{"minlen": f.MinLength},
+			})
+		}
+		if f.MaxLength > 0 && n > f.MaxLength {
+			errs.add(&ValidationError{
+				Field:   f.Name,
+				Rule:    "maxlen",
+				Message: fmt.Sprintf("must be at most %d characters", f.MaxLength),
+				Params:  map[string]interface{}{"maxlen": f.MaxLength},
+			})
+		}
+		if f.Pattern != "" {
+			// The HTML pattern attribute must match the entire value, so we
+			// anchor the expression to get the same behavior.
+			re, err := regexp.Compile("^(?:" + f.Pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("form: invalid pattern for field %v: %w", f.Name, err)
+			}
+			if !re.MatchString(s) {
+				errs.add(&ValidationError{
+					Field:   f.Name,
+					Rule:    "pattern",
+					Message: "is not in the correct format",
+					Params:  map[string]interface{}{"pattern": f.Pattern},
+				})
+			}
+		}
+	}
+	return errs, nil
+}
+
+func validateNumber(f Field, n float64) (Errors, error) {
+	var errs Errors
+	if f.Min != "" {
+		min, err := strconv.ParseFloat(f.Min, 64)
+		if err != nil {
+			return nil, fmt.Errorf("form: invalid min for field %v: %w", f.Name, err)
+		}
+		if n < min {
+			errs.add(&ValidationError{
+				Field:   f.Name,
+				Rule:    "min",
+				Message: fmt.Sprintf("must be at least %v", f.Min),
+				Params:  map[string]interface{}{"min": f.Min},
+			})
+		}
+	}
+	if f.Max != "" {
+		max, err := strconv.ParseFloat(f.Max, 64)
+		if err != nil {
+			return nil, fmt.Errorf("form: invalid max for field %v: %w", f.Name, err)
+		}
+		if n > max {
+			errs.add(&ValidationError{
+				Field:   f.Name,
+				Rule:    "max",
+				Message: fmt.Sprintf("must be at most %v", f.Max),
+				Params:  map[string]interface{}{"max": f.Max},
+			})
+		}
+	}
+	return errs, nil
+}
+
+// ValidationError is returned (inside of an Errors value) by Validate when
+// a field's value doesn't satisfy one of its rules. It implements the
+// FieldErrorer interface so it can be passed back into Inputs to render the
+// error alongside the field.
+type ValidationError struct {
+	// Field is the name of the input, as rendered by the Builder.
+	Field string
+	// Rule is the name of the tag that failed, eg "required" or "minlen".
+	Rule string
+	// Message is the user facing error message, eg "is required".
+	Message string
+	// Params are the values used to build the Message, keyed by the name
+	// of the rule's tag, eg {"minlen": 3}. It is nil for the required
+	// rule.
+	Params map[string]interface{}
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("form: %v %v", e.Field, e.Message)
+}
+
+// FieldError returns the field name and error message.
+func (e *ValidationError) FieldError() (field, err string) {
+	return e.Field, e.Message
+}
+
+// ErrorCode returns the Rule as the error's code, along with its Params.
+func (e *ValidationError) ErrorCode() (code string, params map[string]interface{}) {
+	return e.Rule, e.Params
+}
@@ -0,0 +1,172 @@
+package form
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuilder_Validate(t *testing.T) {
+	type signup struct {
+		Username string  `form:"required;minlen=3;maxlen=8;pattern=[a-z]+"`
+		Age      int     `form:"min=13;max=120"`
+		Rating   float64 `form:"min=0.5"`
+		Terms    bool    `form:"required"`
+		Bio      string  `form:"minlen=10"`
+	}
+	tests := []struct {
+		name string
+		arg  interface{}
+		want []ValidationError
+	}{
+		{
+			name: "valid",
+			arg: signup{
+				Username: "michael",
+				Age:      42,
+				Rating:   1,
+				Terms:    true,
+			},
+			want: nil,
+		}, {
+			name: "required",
+			arg:  signup{Age: 13, Rating: 1},
+			want: []ValidationError{
+				{Field: "Username", Rule: "required", Message: "is required"},
+				{Field: "Terms", Rule: "required", Message: "is required"},
+			},
+		}, {
+			name: "min with zero",
+			arg:  signup{Username: "michael", Terms: true},
+			want: []ValidationError{
+				{Field: "Age", Rule: "min", Message: "must be at least 13", Params: map[string]interface{}{"min": "13"}},
+				{Field: "Rating", Rule: "min", Message: "must be at least 0.5", Params: map[string]interface{}{"min": "0.5"}},
+			},
+		}, {
+			name: "empty values skip rules",
+			arg: struct {
+				Name string        `form:"minlen=3;pattern=[a-z]+"`
+				Age  *int          `form:"min=13"`
+				Born time.Time     `form:"type=date;min=2000-01-01"`
+				Opt  sql.NullInt64 `form:"min=1"`
+			}{},
+			want: nil,
+		}, {
+			name: "lengths and pattern",
+			arg: signup{
+				Username: "Mi",
+				Age:      13,
+				Rating:   1,
+				Terms:    true,
+				Bio:      "short",
+			},
+			want: []ValidationError{
+				{Field: "Username", Rule: "minlen", Message: "must be at least 3 characters", Params: map[string]interface{}{"minlen": 3}},
+				{Field: "Username", Rule: "pattern", Message: "is not in the correct format", Params: map[string]interface{}{"pattern": "[a-z]+"}},
+				{Field: "Bio", Rule: "minlen", Message: "must be at least 10 characters", Params: map[string]interface{}{"minlen": 10}},
+			},
+		}, {
+			name: "min and max",
+			arg: signup{
+				Username: "michael",
+				Age:      200,
+				Rating:   0.25,
+				Terms:    true,
+			},
+			want: []ValidationError{
+				{Field: "Age", Rule: "max", Message: "must be at most 120", Params: map[string]interface{}{"max": "120"}},
+				{Field: "Rating", Rule: "min", Message: "must be at least 0.5", Params: map[string]interface{}{"min": "0.5"}},
+			},
+		}, {
+			name: "required zero values",
+			arg: struct {
+				Opt   *int `form:"required"`
+				Count int  `form:"required"`
+			}{Opt: new(int)},
+			want: nil,
+		}, {
+			name: "required nil pointers",
+			arg: struct {
+				Opt   *int  `form:"required"`
+				Terms *bool `form:"required"`
+			}{Terms: new(bool)},
+			want: []ValidationError{
+				{Field: "Opt", Rule: "required", Message: "is required"},
+				{Field: "Terms", Rule: "required", Message: "is required"},
+			},
+		}, {
+			name: "pattern with equals",
+			arg: struct {
+				Expr string `form:"pattern=a=b"`
+			}{"a=c"},
+			want: []ValidationError{
+				{Field: "Expr", Rule: "pattern", Message: "is not in the correct format", Params: map[string]interface{}{"pattern": "a=b"}},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b Builder
+			err := b.Validate(tc.arg)
+			if tc.want == nil {
+				if err != nil {
+					t.Fatalf("Builder.Validate() err = %v, want %v", err, nil)
+				}
+				return
+			}
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Builder.Validate() err = %v, want Errors", err)
+			}
+			var got []ValidationError
+			for _, err := range errs {
+				var ve *ValidationError
+				if !errors.As(err, &ve) {
+					t.Fatalf("Builder.Validate() err = %v, want *ValidationError", err)
+				}
+				got = append(got, *ve)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Builder.Validate() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuilder_Validate_invalidRule(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  interface{}
+	}{
+		{"pattern", struct {
+			Name string `form:"pattern=[a-"`
+		}{"abc"}},
+		{"min", struct {
+			Age int `form:"min=ten"`
+		}{12}},
+		{"max", struct {
+			Age int `form:"max=ten"`
+		}{12}},
+		{"minlen", struct {
+			Name string `form:"minlen=three"`
+		}{"abc"}},
+		{"maxlen", struct {
+			Name string `form:"maxlen=3.5"`
+		}{"abc"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b Builder
+			err := b.Validate(tc.arg)
+			if err == nil {
+				t.Fatalf("Builder.Validate() err = nil, want an error")
+			}
+			var errs Errors
+			if errors.As(err, &errs) {
+				t.Errorf("Builder.Validate() err = %v, want a non-Errors error", err)
+			}
+		})
+	}
+}